@@ -0,0 +1,238 @@
+//go:build js && wasm
+
+// Command open-ve-wasm is the validation engine built for JavaScript (GOOS=js GOARCH=wasm).
+// It registers the functions of globalThis.__openVE, which wasm/open-ve.mjs wraps in the JavaScript API.
+// Each function returns {result} or {error} since a Go panic would stop the whole instance.
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"syscall/js"
+
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	dslPkg "github.com/shibukazu/open-ve/go/pkg/dsl"
+	"github.com/shibukazu/open-ve/go/pkg/dsl/binding"
+	"github.com/shibukazu/open-ve/go/pkg/dsl/bundle"
+	"github.com/shibukazu/open-ve/go/pkg/dsl/util"
+	"github.com/shibukazu/open-ve/go/pkg/engine"
+	"github.com/shibukazu/open-ve/go/pkg/store"
+	"gopkg.in/yaml.v3"
+)
+
+type instance struct {
+	engine *engine.Engine
+}
+
+// bundleResponse is the JSON of GET /v1/dsl/bundle, in which bytes are base64 encoded.
+type bundleResponse struct {
+	Bundle    string `json:"bundle"`
+	Signature string `json:"signature"`
+	KeyID     string `json:"keyId"`
+}
+
+type checkOptions struct {
+	Profile string `json:"profile"`
+	Explain bool   `json:"explain"`
+}
+
+type checkResult struct {
+	IsValid      bool                 `json:"isValid"`
+	Message      string               `json:"message"`
+	Errors       []engine.RuleError   `json:"errors"`
+	Explanations []engine.Explanation `json:"explanations,omitempty"`
+	Computed     map[string]string    `json:"computed,omitempty"`
+}
+
+func main() {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	e := &instance{
+		engine: engine.New(engine.WithLogger(logger), engine.WithStore(store.NewMemoryStore("wasm"))),
+	}
+
+	js.Global().Set("__openVE", js.ValueOf(map[string]interface{}{
+		"loadDSL":    jsFunc(e.loadDSL),
+		"loadBundle": jsFunc(e.loadBundle),
+		"check":      jsFunc(e.check),
+	}))
+	select {}
+}
+
+// jsFunc passes the arguments as JSON text, which the JavaScript API stringifies.
+func jsFunc(f func(args []string) (interface{}, error)) js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		texts := make([]string, len(args))
+		for i, arg := range args {
+			if arg.Type() == js.TypeString {
+				texts[i] = arg.String()
+			}
+		}
+		result, err := f(texts)
+		if err != nil {
+			return js.ValueOf(map[string]interface{}{"error": errorMessage(err), "code": fmt.Sprint(failure.CodeOf(err))})
+		}
+		encoded, err := json.Marshal(result)
+		if err != nil {
+			return js.ValueOf(map[string]interface{}{"error": err.Error()})
+		}
+		return js.ValueOf(map[string]interface{}{"result": string(encoded)})
+	})
+}
+
+// errorMessage returns the message of the error with its cause, without the call stack.
+func errorMessage(err error) string {
+	message := fmt.Sprint(failure.MessageOf(err))
+	if cause := failure.CauseOf(err); cause != nil {
+		if message == "" {
+			return cause.Error()
+		}
+		return message + ": " + cause.Error()
+	}
+	if message == "" {
+		return err.Error()
+	}
+	return message
+}
+
+// loadDSL registers a DSL written in YAML or JSON: loadDSL(source).
+func (e *instance) loadDSL(args []string) (interface{}, error) {
+	if len(args) < 1 {
+		return nil, failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("DSL is required"))
+	}
+	dsl := &dslPkg.DSL{}
+	if err := yaml.Unmarshal([]byte(args[0]), dsl); err != nil {
+		return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to parse DSL"))
+	}
+	if err := e.engine.Load(context.Background(), dsl); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// loadBundle registers the bundle of GET /v1/dsl/bundle: loadBundle(response, publicKeyPEM).
+// The signature is verified if the public key is given.
+func (e *instance) loadBundle(args []string) (interface{}, error) {
+	if len(args) < 1 {
+		return nil, failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("bundle is required"))
+	}
+	var res bundleResponse
+	if err := json.Unmarshal([]byte(args[0]), &res); err != nil {
+		return nil, failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("failed to decode bundle response"))
+	}
+	data, err := base64.StdEncoding.DecodeString(res.Bundle)
+	if err != nil {
+		return nil, failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("bundle must be base64 encoded"))
+	}
+	if len(args) > 1 && args[1] != "" {
+		publicKey, err := parsePublicKey(args[1])
+		if err != nil {
+			return nil, err
+		}
+		signature, err := base64.StdEncoding.DecodeString(res.Signature)
+		if err != nil || !bundle.Verify(publicKey, data, signature) {
+			return nil, failure.New(appError.ErrAuthenticationFailed, failure.Messagef("invalid bundle signature"))
+		}
+	}
+	if err := e.engine.LoadBundle(context.Background(), data); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func parsePublicKey(publicKeyPEM string) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if block == nil {
+		return nil, failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("public key is not PEM encoded"))
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("failed to parse public key"))
+	}
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("public key must be an Ed25519 key"))
+	}
+	return publicKey, nil
+}
+
+// check validates variables given as in the JSON check request: check(id, variables, options).
+func (e *instance) check(args []string) (interface{}, error) {
+	if len(args) < 2 {
+		return nil, failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("id and variables are required"))
+	}
+	ctx := context.Background()
+	id := args[0]
+	var options checkOptions
+	if len(args) > 2 && args[2] != "" {
+		if err := json.Unmarshal([]byte(args[2]), &options); err != nil {
+			return nil, failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("failed to decode options"))
+		}
+	}
+	var values map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(args[1]))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return nil, failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("variables must be an object"))
+	}
+	variables, err := e.convertVariables(ctx, id, values)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []engine.CheckOption{engine.WithProfile(options.Profile)}
+	if options.Explain {
+		opts = append(opts, engine.WithExplain())
+	}
+	result, err := e.engine.Check(ctx, id, variables, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return checkResult{
+		IsValid:      result.IsValid,
+		Message:      result.Message,
+		Errors:       result.Errors,
+		Explanations: result.Explanations,
+		Computed:     result.Computed,
+	}, nil
+}
+
+// convertVariables converts the decoded JSON values by the declared types as the gateway does.
+func (e *instance) convertVariables(ctx context.Context, id string, values map[string]interface{}) (map[string]interface{}, error) {
+	variableNameToCELType, err := e.engine.VariableTypes(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	files, err := e.engine.Files(ctx)
+	if err != nil {
+		return nil, err
+	}
+	variables := make(map[string]interface{}, len(values))
+	for name, value := range values {
+		celType, ok := variableNameToCELType[name]
+		if !ok {
+			return nil, failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("variable %s is not declared", name))
+		}
+		if util.IsPrimitiveType(celType) {
+			variables[name], err = binding.Convert(value, celType)
+		} else {
+			md, mdErr := util.FindMessageDescriptor(files, celType)
+			if mdErr != nil {
+				return nil, mdErr
+			}
+			variables[name], err = binding.ConvertMessage(value, md)
+		}
+		if err != nil {
+			return nil, failure.Wrap(err, failure.Messagef("invalid variable %s", name))
+		}
+	}
+	return variables, nil
+}
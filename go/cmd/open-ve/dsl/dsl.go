@@ -0,0 +1,171 @@
+package dsl
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/shibukazu/open-ve/go/cmd/open-ve/run"
+	"github.com/shibukazu/open-ve/go/pkg/dsl/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+func NewDSLCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dsl",
+		Short: "Export and import the DSL of a server or a store",
+		Long:  "Export and import the DSL of a running server or of the store engine configured for open-ve run, in the YAML format of open-ve test.",
+	}
+
+	flags := cmd.PersistentFlags()
+
+	flags.String("server", "http://localhost:8080", "Server address: http(s)://host:port for HTTP or grpc(s)://host:port for gRPC")
+	run.MustBindPFlag("dsl.server", flags.Lookup("server"))
+	viper.MustBindEnv("dsl.server", "OPEN-VE_DSL_SERVER")
+
+	flags.String("preshared-key", "", "Preshared key sent to the server")
+	run.MustBindPFlag("dsl.presharedKey", flags.Lookup("preshared-key"))
+	viper.MustBindEnv("dsl.presharedKey", "OPEN-VE_DSL_PRESHARED_KEY")
+
+	flags.String("tls-ca-path", "", "CA certificate (PEM) the server certificate is verified with. The system roots are used if empty")
+	run.MustBindPFlag("dsl.tlsCAPath", flags.Lookup("tls-ca-path"))
+	viper.MustBindEnv("dsl.tlsCAPath", "OPEN-VE_DSL_TLS_CA_PATH")
+
+	flags.String("namespace", "", "Namespace of the DSL. The default namespace is used if empty")
+	run.MustBindPFlag("dsl.namespace", flags.Lookup("namespace"))
+	viper.MustBindEnv("dsl.namespace", "OPEN-VE_DSL_NAMESPACE")
+
+	flags.Bool("store", false, "Access the store engine configured for open-ve run instead of a server")
+	run.MustBindPFlag("dsl.store", flags.Lookup("store"))
+
+	cmd.AddCommand(newExportCommand())
+	cmd.AddCommand(newImportCommand())
+	return cmd
+}
+
+func newExportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the DSL with the contents of the datasets",
+		Long:  "Export the DSL with the contents of the datasets. The file descriptor set, if any, is written next to the output as <output>.fds.",
+		Run:   export,
+		Args:  cobra.NoArgs,
+	}
+	cmd.Flags().StringP("output", "o", "", "Output file. The DSL is written to stdout if empty")
+	return cmd
+}
+
+func newImportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import <dsl file>",
+		Short: "Import a DSL file with the datasets and the file descriptor set it refers to",
+		Long:  "Import a DSL file with the datasets and the file descriptor set it refers to, replacing the registered DSL.",
+		Run:   importDSL,
+		Args:  validateImportArgs,
+	}
+	return cmd
+}
+
+func validateImportArgs(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("requires exactly one argument: <dsl file>")
+	}
+
+	if _, err := os.Stat(args[0]); os.IsNotExist(err) {
+		return fmt.Errorf("the open-ve schema file %s does not exist", args[0])
+	}
+
+	return nil
+}
+
+// newLogger logs to stderr, so that the exported DSL can be written to stdout.
+func newLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo}))
+}
+
+func export(cmd *cobra.Command, args []string) {
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		panic(err)
+	}
+
+	logger := newLogger()
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	target, err := newTarget(ctx)
+	if err != nil {
+		panic(fmt.Errorf("failed to connect: %w", err))
+	}
+	defer target.Close()
+	logger.Info("📤 exporting open-ve schema", slog.String("from", target.String()))
+
+	dsl, version, err := target.Export(ctx)
+	if err != nil {
+		panic(fmt.Errorf("failed to export schema: %w", err))
+	}
+	dsl.Version = version
+
+	if len(dsl.FileDescriptorSet) > 0 {
+		if output == "" {
+			panic(fmt.Errorf("--output is required to export the file descriptor set"))
+		}
+		fileDescriptorSetPath := strings.TrimSuffix(output, filepath.Ext(output)) + ".fds"
+		if err := os.WriteFile(fileDescriptorSetPath, dsl.FileDescriptorSet, 0644); err != nil {
+			panic(fmt.Errorf("failed to write file descriptor set: %w", err))
+		}
+		dsl.FileDescriptorSetPath = filepath.Base(fileDescriptorSetPath)
+	}
+
+	serialized, err := yaml.Marshal(dsl)
+	if err != nil {
+		panic(fmt.Errorf("failed to serialize schema: %w", err))
+	}
+	if output == "" {
+		_, err = os.Stdout.Write(serialized)
+	} else {
+		err = os.WriteFile(output, serialized, 0644)
+	}
+	if err != nil {
+		panic(fmt.Errorf("failed to write schema: %w", err))
+	}
+
+	logger.Info("✅ exported open-ve schema", slog.String("version", version), slog.Int("validations", len(dsl.Validations)), slog.Int("datasets", len(dsl.Datasets)))
+}
+
+func importDSL(cmd *cobra.Command, args []string) {
+	filePath := args[0]
+
+	logger := newLogger()
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	dsl, err := util.ParseDSLYAML(filePath)
+	if err != nil {
+		panic(fmt.Errorf("failed to parse schema: %w", err))
+	}
+
+	target, err := newTarget(ctx)
+	if err != nil {
+		panic(fmt.Errorf("failed to connect: %w", err))
+	}
+	defer target.Close()
+	logger.Info("📥 importing open-ve schema", slog.String("filePath", filePath), slog.String("to", target.String()))
+
+	version, err := target.Import(ctx, dsl)
+	if err != nil {
+		panic(fmt.Errorf("failed to import schema: %w", err))
+	}
+	if dsl.Version != "" && dsl.Version != version {
+		logger.Warn("⚠️ the version differs from the exported one: the schema was edited after the export", slog.String("exported", dsl.Version), slog.String("imported", version))
+	}
+
+	logger.Info("✅ imported open-ve schema", slog.String("version", version), slog.Int("validations", len(dsl.Validations)), slog.Int("datasets", len(dsl.Datasets)))
+}
@@ -0,0 +1,359 @@
+package dsl
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/shibukazu/open-ve/go/pkg/config"
+	dslPkg "github.com/shibukazu/open-ve/go/pkg/dsl"
+	"github.com/shibukazu/open-ve/go/pkg/engine"
+	"github.com/shibukazu/open-ve/go/pkg/namespace"
+	dslv1 "github.com/shibukazu/open-ve/go/pkg/services/dsl/v1"
+	storePkg "github.com/shibukazu/open-ve/go/pkg/store"
+	pb "github.com/shibukazu/open-ve/go/proto/dsl/v1"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// target is a server or a store the DSL is exported from and imported to.
+type target interface {
+	// Export returns the DSL with the contents of the datasets and its version.
+	Export(ctx context.Context) (*dslPkg.DSL, string, error)
+	// Import replaces the DSL and the contents of the datasets, and returns the version of the registered schema.
+	Import(ctx context.Context, dsl *dslPkg.DSL) (string, error)
+	Close() error
+	String() string
+}
+
+func newTarget(ctx context.Context) (target, error) {
+	namespaceName := viper.GetString("dsl.namespace")
+	if namespaceName != "" {
+		if err := namespace.ValidateName(namespaceName); err != nil {
+			return nil, err
+		}
+	}
+	if viper.GetBool("dsl.store") {
+		return newStoreTarget(ctx, namespaceName)
+	}
+	return newServerTarget(viper.GetString("dsl.server"), viper.GetString("dsl.presharedKey"), viper.GetString("dsl.tlsCAPath"), namespaceName)
+}
+
+// dslClient is the part of pb.DSLServiceClient used by serverTarget, also implemented over HTTP by httpClient.
+type dslClient interface {
+	Read(ctx context.Context, in *pb.ReadRequest, opts ...grpc.CallOption) (*pb.ReadResponse, error)
+	Register(ctx context.Context, in *pb.RegisterRequest, opts ...grpc.CallOption) (*pb.RegisterResponse, error)
+	ReadDataset(ctx context.Context, in *pb.ReadDatasetRequest, opts ...grpc.CallOption) (*pb.ReadDatasetResponse, error)
+}
+
+type serverTarget struct {
+	address string
+	client  dslClient
+	conn    *grpc.ClientConn
+}
+
+func newServerTarget(address string, presharedKey string, caPath string, namespaceName string) (*serverTarget, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server address %s: %w", address, err)
+	}
+	var tlsConfig *tls.Config
+	if u.Scheme == "https" || u.Scheme == "grpcs" {
+		tlsConfig = &tls.Config{}
+		if caPath != "" {
+			ca, err := os.ReadFile(caPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+			}
+			tlsConfig.RootCAs = x509.NewCertPool()
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+				return nil, fmt.Errorf("no certificate found in %s", caPath)
+			}
+		}
+	}
+
+	// The namespace is shown as the path prefix selecting it
+	name := address
+	if namespaceName != "" {
+		name = strings.TrimSuffix(address, "/") + namespace.PathPrefix + namespaceName
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		client := &http.Client{}
+		if tlsConfig != nil {
+			client.Transport = &http.Transport{TLSClientConfig: tlsConfig}
+		}
+		return &serverTarget{
+			address: name,
+			client:  &httpClient{address: u.Scheme + "://" + u.Host, presharedKey: presharedKey, namespace: namespaceName, client: client},
+		}, nil
+	case "grpc", "grpcs":
+		creds := insecure.NewCredentials()
+		if tlsConfig != nil {
+			creds = credentials.NewTLS(tlsConfig)
+		}
+		opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+		if presharedKey != "" {
+			opts = append(opts, grpc.WithPerRPCCredentials(bearerCredentials(presharedKey)))
+		}
+		if namespaceName != "" {
+			opts = append(opts, grpc.WithUnaryInterceptor(namespaceInterceptor(namespaceName)))
+		}
+		conn, err := grpc.NewClient(u.Host, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gRPC client: %w", err)
+		}
+		return &serverTarget{address: name, client: pb.NewDSLServiceClient(conn), conn: conn}, nil
+	default:
+		return nil, fmt.Errorf("unsupported scheme of server address %s: http, https, grpc or grpcs is expected", address)
+	}
+}
+
+func (t *serverTarget) Export(ctx context.Context) (*dslPkg.DSL, string, error) {
+	res, err := t.client.Read(ctx, &pb.ReadRequest{})
+	if err != nil {
+		return nil, "", err
+	}
+	datasets := make([]*pb.Dataset, len(res.Datasets))
+	for i, dataset := range res.Datasets {
+		datasetRes, err := t.client.ReadDataset(ctx, &pb.ReadDatasetRequest{Name: dataset.Name})
+		if err != nil {
+			return nil, "", err
+		}
+		datasets[i] = datasetRes.Dataset
+	}
+	dsl, err := dslv1.ToDSL(&pb.RegisterRequest{Validations: res.Validations, Datasets: datasets, FileDescriptorSet: res.FileDescriptorSet})
+	if err != nil {
+		return nil, "", err
+	}
+	return dsl, res.Version, nil
+}
+
+func (t *serverTarget) Import(ctx context.Context, dsl *dslPkg.DSL) (string, error) {
+	schema, err := dslv1.ToProto(dsl)
+	if err != nil {
+		return "", err
+	}
+	req := &pb.RegisterRequest{Validations: schema.Validations, FileDescriptorSet: dsl.FileDescriptorSet}
+	for _, dataset := range dsl.Datasets {
+		req.Datasets = append(req.Datasets, &pb.Dataset{Name: dataset.Name, Kind: dataset.Kind, Values: dataset.Values, Entries: dataset.Entries})
+	}
+	if _, err := t.client.Register(ctx, req); err != nil {
+		return "", err
+	}
+	res, err := t.client.Read(ctx, &pb.ReadRequest{})
+	if err != nil {
+		return "", err
+	}
+	return res.Version, nil
+}
+
+func (t *serverTarget) Close() error {
+	if t.conn != nil {
+		return t.conn.Close()
+	}
+	return nil
+}
+
+func (t *serverTarget) String() string {
+	return t.address
+}
+
+// bearerCredentials sends the preshared key as the servers expect it, also over connections without TLS.
+type bearerCredentials string
+
+func (c bearerCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(c)}, nil
+}
+
+func (c bearerCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// namespaceInterceptor selects the namespace of the calls.
+func namespaceInterceptor(name string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(metadata.AppendToOutgoingContext(ctx, namespace.MetadataKey, name), method, req, reply, cc, opts...)
+	}
+}
+
+// httpClient calls the DSL service through the HTTP gateway.
+type httpClient struct {
+	address      string
+	presharedKey string
+	// namespace is sent as the namespace header if not empty.
+	namespace string
+	client    *http.Client
+}
+
+func (c *httpClient) Read(ctx context.Context, in *pb.ReadRequest, opts ...grpc.CallOption) (*pb.ReadResponse, error) {
+	res := &pb.ReadResponse{}
+	if err := c.do(ctx, http.MethodGet, "/v1/dsl", nil, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *httpClient) Register(ctx context.Context, in *pb.RegisterRequest, opts ...grpc.CallOption) (*pb.RegisterResponse, error) {
+	res := &pb.RegisterResponse{}
+	if err := c.do(ctx, http.MethodPost, "/v1/dsl", in, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *httpClient) ReadDataset(ctx context.Context, in *pb.ReadDatasetRequest, opts ...grpc.CallOption) (*pb.ReadDatasetResponse, error) {
+	res := &pb.ReadDatasetResponse{}
+	if err := c.do(ctx, http.MethodGet, "/v1/datasets/"+url.PathEscape(in.Name), nil, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *httpClient) do(ctx context.Context, method string, path string, in proto.Message, out proto.Message) error {
+	var body io.Reader
+	if in != nil {
+		encoded, err := protojson.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.address+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.presharedKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.presharedKey)
+	}
+	if c.namespace != "" {
+		req.Header.Set(namespace.Header, c.namespace)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, bytes.TrimSpace(data))
+	}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// storeTarget accesses the store engine configured for open-ve run, with the same configuration file and environment variables.
+type storeTarget struct {
+	engine *engine.Engine
+	name   string
+	close  func() error
+}
+
+func newStoreTarget(ctx context.Context, namespaceName string) (*storeTarget, error) {
+	if err := viper.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			return nil, err
+		}
+	}
+	var cfg config.Config
+	if err := viper.Unmarshal(&cfg); err != nil {
+		return nil, err
+	}
+
+	var nodeId string
+	if cfg.Mode == "master" {
+		nodeId = "master"
+	} else {
+		nodeId = cfg.Slave.Id
+	}
+
+	t := &storeTarget{close: func() error { return nil }}
+	var store storePkg.Store
+	switch cfg.Store.Engine {
+	case "redis":
+		redisClient, err := storePkg.NewRedisClient(&cfg.Store.Redis)
+		if err != nil {
+			return nil, err
+		}
+		redisStore := storePkg.NewRedisStore(nodeId, redisClient)
+		if err := redisStore.Migrate(ctx); err != nil {
+			redisClient.Close()
+			return nil, err
+		}
+		store = redisStore
+		t.name = "redis://" + cfg.Store.Redis.Addr
+		t.close = redisClient.Close
+	case "bolt":
+		boltStore, err := storePkg.NewBoltStore(nodeId, cfg.Store.Bolt.Path)
+		if err != nil {
+			return nil, err
+		}
+		store = boltStore
+		t.name = "bolt://" + cfg.Store.Bolt.Path
+		t.close = boltStore.Close
+	default:
+		return nil, fmt.Errorf("the %s store engine can't be accessed from another process: redis or bolt is expected", cfg.Store.Engine)
+	}
+	if namespaceName != "" {
+		namespacer, ok := store.(storePkg.Namespacer)
+		if !ok {
+			t.close()
+			return nil, fmt.Errorf("the %s store engine does not support namespaces", cfg.Store.Engine)
+		}
+		if _, err := namespacer.ReadNamespace(ctx, namespaceName); err != nil {
+			t.close()
+			return nil, err
+		}
+		store = namespacer.Namespace(namespaceName)
+		t.name += namespace.PathPrefix + namespaceName
+	}
+	t.engine = engine.New(engine.WithStore(store))
+	return t, nil
+}
+
+func (t *storeTarget) Export(ctx context.Context) (*dslPkg.DSL, string, error) {
+	dsl, err := t.engine.DSL(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	return dsl, dsl.Version, nil
+}
+
+func (t *storeTarget) Import(ctx context.Context, dsl *dslPkg.DSL) (string, error) {
+	if err := t.engine.Load(ctx, dsl); err != nil {
+		return "", err
+	}
+	registered, err := t.engine.Schema(ctx)
+	if err != nil {
+		return "", err
+	}
+	return registered.Version, nil
+}
+
+func (t *storeTarget) Close() error {
+	return t.close()
+}
+
+func (t *storeTarget) String() string {
+	return t.name
+}
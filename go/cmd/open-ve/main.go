@@ -1,6 +1,7 @@
 package main
 
 import (
+	"github.com/shibukazu/open-ve/go/cmd/open-ve/dsl"
 	"github.com/shibukazu/open-ve/go/cmd/open-ve/gen"
 	"github.com/shibukazu/open-ve/go/cmd/open-ve/run"
 	"github.com/shibukazu/open-ve/go/cmd/open-ve/test"
@@ -22,6 +23,8 @@ func main() {
 	rootCmd.AddCommand(genCmd)
 	testCmd := test.NewTestCommand()
 	rootCmd.AddCommand(testCmd)
+	dslCmd := dsl.NewDSLCommand()
+	rootCmd.AddCommand(dslCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		panic(err)
@@ -8,17 +8,17 @@ import (
 	"sync"
 	"syscall"
 
-	"github.com/go-redis/redis"
 	"github.com/morikuni/failure/v2"
 	"github.com/shibukazu/open-ve/go/pkg/appError"
 	"github.com/shibukazu/open-ve/go/pkg/authn"
 	"github.com/shibukazu/open-ve/go/pkg/config"
-	"github.com/shibukazu/open-ve/go/pkg/dsl/reader"
+	"github.com/shibukazu/open-ve/go/pkg/dsl/bundle"
+	"github.com/shibukazu/open-ve/go/pkg/engine"
 	"github.com/shibukazu/open-ve/go/pkg/logger"
+	"github.com/shibukazu/open-ve/go/pkg/namespace"
 	"github.com/shibukazu/open-ve/go/pkg/server"
 	"github.com/shibukazu/open-ve/go/pkg/slave"
 	storePkg "github.com/shibukazu/open-ve/go/pkg/store"
-	"github.com/shibukazu/open-ve/go/pkg/validator"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -104,6 +104,10 @@ func NewRunCommand() *cobra.Command {
 	flags.String("http-tls-key-path", defaultConfig.Http.TLS.KeyPath, "HTTP server TLS key path")
 	MustBindPFlag("http.tls.keyPath", flags.Lookup("http-tls-key-path"))
 	viper.MustBindEnv("http.tls.keyPath", "OPEN-VE_HTTP_TLS_KEY_PATH")
+
+	flags.Int64("http-form-max-part-size", defaultConfig.Http.FormMaxPartSize, "Maximum size in bytes of each part of a form check request")
+	MustBindPFlag("http.formMaxPartSize", flags.Lookup("http-form-max-part-size"))
+	viper.MustBindEnv("http.formMaxPartSize", "OPEN-VE_HTTP_FORM_MAX_PART_SIZE")
 	// GRPC
 	flags.String("grpc-port", defaultConfig.GRPC.Port, "gRPC server port")
 	MustBindPFlag("grpc.port", flags.Lookup("grpc-port"))
@@ -120,15 +124,39 @@ func NewRunCommand() *cobra.Command {
 	flags.String("grpc-tls-key-path", defaultConfig.GRPC.TLS.KeyPath, "gRPC server TLS key path")
 	MustBindPFlag("grpc.tls.keyPath", flags.Lookup("grpc-tls-key-path"))
 	viper.MustBindEnv("grpc.tls.keyPath", "OPEN-VE_GRPC_TLS_KEY_PATH")
+
+	flags.Int("grpc-stream-concurrency", defaultConfig.GRPC.StreamConcurrency, "Maximum number of requests evaluated concurrently per gRPC stream")
+	MustBindPFlag("grpc.streamConcurrency", flags.Lookup("grpc-stream-concurrency"))
+	viper.MustBindEnv("grpc.streamConcurrency", "OPEN-VE_GRPC_STREAM_CONCURRENCY")
+
+	flags.StringSlice("grpc-context-metadata", defaultConfig.GRPC.ContextMetadata, "gRPC metadata keys (or HTTP headers) exposed to rules as openve.request.metadata")
+	MustBindPFlag("grpc.contextMetadata", flags.Lookup("grpc-context-metadata"))
+	viper.MustBindEnv("grpc.contextMetadata", "OPEN-VE_GRPC_CONTEXT_METADATA")
 	// Store
-	flags.String("store-engine", defaultConfig.Store.Engine, "store engine (memory, redis)")
+	flags.String("store-engine", defaultConfig.Store.Engine, "store engine (memory, redis, bolt)")
 	MustBindPFlag("store.engine", flags.Lookup("store-engine"))
 	viper.MustBindEnv("store.engine", "OPEN-VE_STORE_ENGINE")
 
+	flags.String("store-redis-mode", defaultConfig.Store.Redis.Mode, "Redis mode (standalone, sentinel, cluster)")
+	MustBindPFlag("store.redis.mode", flags.Lookup("store-redis-mode"))
+	viper.MustBindEnv("store.redis.mode", "OPEN-VE_STORE_REDIS_MODE")
+
 	flags.String("store-redis-addr", defaultConfig.Store.Redis.Addr, "Redis address")
 	MustBindPFlag("store.redis.addr", flags.Lookup("store-redis-addr"))
 	viper.MustBindEnv("store.redis.addr", "OPEN-VE_STORE_REDIS_ADDR")
 
+	flags.StringSlice("store-redis-addrs", defaultConfig.Store.Redis.Addrs, "Redis sentinel or cluster node addresses")
+	MustBindPFlag("store.redis.addrs", flags.Lookup("store-redis-addrs"))
+	viper.MustBindEnv("store.redis.addrs", "OPEN-VE_STORE_REDIS_ADDRS")
+
+	flags.String("store-redis-master-name", defaultConfig.Store.Redis.MasterName, "Redis master name monitored by the sentinels")
+	MustBindPFlag("store.redis.masterName", flags.Lookup("store-redis-master-name"))
+	viper.MustBindEnv("store.redis.masterName", "OPEN-VE_STORE_REDIS_MASTER_NAME")
+
+	flags.String("store-redis-username", defaultConfig.Store.Redis.Username, "Redis ACL username")
+	MustBindPFlag("store.redis.username", flags.Lookup("store-redis-username"))
+	viper.MustBindEnv("store.redis.username", "OPEN-VE_STORE_REDIS_USERNAME")
+
 	flags.String("store-redis-password", defaultConfig.Store.Redis.Password, "Redis password")
 	MustBindPFlag("store.redis.password", flags.Lookup("store-redis-password"))
 	viper.MustBindEnv("store.redis.password", "OPEN-VE_STORE_REDIS_PASSWORD")
@@ -141,6 +169,30 @@ func NewRunCommand() *cobra.Command {
 	MustBindPFlag("store.redis.poolSize", flags.Lookup("store-redis-pool-size"))
 	viper.MustBindEnv("store.redis.poolSize", "OPEN-VE_STORE_REDIS_POOL_SIZE")
 
+	flags.Bool("store-redis-tls-enabled", defaultConfig.Store.Redis.TLS.Enabled, "Redis TLS enabled")
+	MustBindPFlag("store.redis.tls.enabled", flags.Lookup("store-redis-tls-enabled"))
+	viper.MustBindEnv("store.redis.tls.enabled", "OPEN-VE_STORE_REDIS_TLS_ENABLED")
+
+	flags.String("store-redis-tls-ca-path", defaultConfig.Store.Redis.TLS.CAPath, "Redis TLS CA certificate path")
+	MustBindPFlag("store.redis.tls.caPath", flags.Lookup("store-redis-tls-ca-path"))
+	viper.MustBindEnv("store.redis.tls.caPath", "OPEN-VE_STORE_REDIS_TLS_CA_PATH")
+
+	flags.String("store-redis-tls-cert-path", defaultConfig.Store.Redis.TLS.CertPath, "Redis TLS client certificate path")
+	MustBindPFlag("store.redis.tls.certPath", flags.Lookup("store-redis-tls-cert-path"))
+	viper.MustBindEnv("store.redis.tls.certPath", "OPEN-VE_STORE_REDIS_TLS_CERT_PATH")
+
+	flags.String("store-redis-tls-key-path", defaultConfig.Store.Redis.TLS.KeyPath, "Redis TLS client key path")
+	MustBindPFlag("store.redis.tls.keyPath", flags.Lookup("store-redis-tls-key-path"))
+	viper.MustBindEnv("store.redis.tls.keyPath", "OPEN-VE_STORE_REDIS_TLS_KEY_PATH")
+
+	flags.String("store-bolt-path", defaultConfig.Store.Bolt.Path, "Bolt store file path")
+	MustBindPFlag("store.bolt.path", flags.Lookup("store-bolt-path"))
+	viper.MustBindEnv("store.bolt.path", "OPEN-VE_STORE_BOLT_PATH")
+
+	flags.Duration("store-change-check-interval", defaultConfig.Store.ChangeCheckInterval, "Interval of the store version check")
+	MustBindPFlag("store.changeCheckInterval", flags.Lookup("store-change-check-interval"))
+	viper.MustBindEnv("store.changeCheckInterval", "OPEN-VE_STORE_CHANGE_CHECK_INTERVAL")
+
 	// Log
 	flags.String("log-level", defaultConfig.Log.Level, "Log level")
 	MustBindPFlag("log.level", flags.Lookup("log-level"))
@@ -155,6 +207,14 @@ func NewRunCommand() *cobra.Command {
 	MustBindPFlag("authn.preshared.key", flags.Lookup("authn-preshared-key"))
 	viper.MustBindEnv("authn.preshared.key", "OPEN-VE_AUTHN_PRESHARED_KEY")
 
+	flags.String("authn-preshared-privileged-key", defaultConfig.Authn.Preshared.PrivilegedKey, "Preshared key granted privileged permissions (explain)")
+	MustBindPFlag("authn.preshared.privilegedKey", flags.Lookup("authn-preshared-privileged-key"))
+	viper.MustBindEnv("authn.preshared.privilegedKey", "OPEN-VE_AUTHN_PRESHARED_PRIVILEGED_KEY")
+
+	flags.String("bundle-signing-key-path", defaultConfig.Bundle.SigningKeyPath, "Ed25519 private key (PEM, PKCS #8) the compiled bundles are signed with")
+	MustBindPFlag("bundle.signingKeyPath", flags.Lookup("bundle-signing-key-path"))
+	viper.MustBindEnv("bundle.signingKeyPath", "OPEN-VE_BUNDLE_SIGNING_KEY_PATH")
+
 	return cmd
 }
 
@@ -202,34 +262,54 @@ func run(cmd *cobra.Command, args []string) {
 	var store storePkg.Store
 	switch cfg.Store.Engine {
 	case "redis":
-		redis := redis.NewClient(&redis.Options{
-			Addr:     cfg.Store.Redis.Addr,
-			Password: cfg.Store.Redis.Password,
-			DB:       cfg.Store.Redis.DB,
-			PoolSize: cfg.Store.Redis.PoolSize,
-		})
-		store = storePkg.NewRedisStore(nodeId, redis)
+		redisClient, err := storePkg.NewRedisClient(&cfg.Store.Redis)
+		if err != nil {
+			panic(err)
+		}
+		redisStore := storePkg.NewRedisStore(nodeId, redisClient)
+		if err := redisStore.Migrate(ctx); err != nil {
+			panic(err)
+		}
+		store = redisStore
+	case "bolt":
+		boltStore, err := storePkg.NewBoltStore(nodeId, cfg.Store.Bolt.Path)
+		if err != nil {
+			panic(err)
+		}
+		defer boltStore.Close()
+		store = boltStore
 	case "memory":
 		store = storePkg.NewMemoryStore(nodeId)
 	default:
 		panic("invalid store engine")
 	}
 
-	dslReader := reader.NewDSLReader(logger, store)
-	validator := validator.NewValidator(logger, store)
+	eng := engine.New(engine.WithLogger(logger), engine.WithStore(store))
+	namespaces := namespace.NewManager(logger, store, eng)
 	slaveManager := slave.NewSlaveManager(logger)
-	slaveRegistrar := slave.NewSlaveRegistrar(cfg.Slave.Id, cfg.Slave.SlaveHTTPAddr, cfg.GRPC.TLS.Enabled, cfg.Authn, cfg.Slave.MasterHTTPAddr, cfg.Slave.MasterAuthn, dslReader, logger)
-	var authenticator authn.Authenticator
+	slaveRegistrar := slave.NewSlaveRegistrar(cfg.Slave.Id, cfg.Slave.SlaveHTTPAddr, cfg.GRPC.TLS.Enabled, cfg.Authn, cfg.Slave.MasterHTTPAddr, cfg.Slave.MasterAuthn, eng, logger)
+	var nodeAuthenticator authn.Authenticator
 	switch cfg.Authn.Method {
 	case "preshared":
 		logger.Info("🔐 authenticator: preshared key")
-		authenticator = authn.NewPresharedKeyAuthenticator(cfg.Authn.Preshared.Key)
+		nodeAuthenticator = authn.NewPresharedKeyAuthenticator(cfg.Authn.Preshared.Key, cfg.Authn.Preshared.PrivilegedKey)
 	default:
 		logger.Warn("🔓 authenticator: none")
-		authenticator = &authn.NoopAuthenticator{}
+		nodeAuthenticator = &authn.NoopAuthenticator{}
+	}
+	// The keys of the namespaces are enforced whatever the method of the node
+	authenticator := namespace.NewAuthenticator(nodeAuthenticator, namespaces)
+
+	var bundleSigner *bundle.Signer
+	if cfg.Bundle.SigningKeyPath != "" {
+		bundleSigner, err = bundle.LoadSigner(cfg.Bundle.SigningKeyPath)
+		if err != nil {
+			panic(err)
+		}
+		logger.Info("🔏 bundle signer: ed25519", slog.String("keyID", bundleSigner.KeyID()))
 	}
 
-	gw := server.NewGateway(cfg.Mode, &cfg.Http, &cfg.GRPC, logger, dslReader, slaveManager)
+	gw := server.NewGateway(cfg.Mode, &cfg.Http, &cfg.GRPC, logger, namespaces, slaveManager, authenticator)
 	wg.Add(1)
 
 	logger.Info("🚀 Open-VE: starting...", slog.Any("config", cfg))
@@ -238,13 +318,19 @@ func run(cmd *cobra.Command, args []string) {
 		gw.Run(ctx, wg)
 	}(wg)
 
-	grpc := server.NewGrpc(cfg.Mode, &cfg.GRPC, logger, validator, dslReader, slaveManager, slaveRegistrar, authenticator)
+	grpc := server.NewGrpc(cfg.Mode, &cfg.GRPC, logger, namespaces, slaveManager, slaveRegistrar, authenticator, bundleSigner)
 	wg.Add(1)
 	go func(wg *sync.WaitGroup) {
 		logger.Info("🚀 grpc server: starting..")
 		grpc.Run(ctx, wg, cfg.Mode)
 	}(wg)
 
+	wg.Add(1)
+	go func(wg *sync.WaitGroup) {
+		logger.Info("🚀 store watcher: starting..")
+		namespaces.Watch(ctx, wg, cfg.Store.ChangeCheckInterval)
+	}(wg)
+
 	if cfg.Mode == "slave" {
 		wg.Add(1)
 		go func(wg *sync.WaitGroup) {
@@ -0,0 +1,59 @@
+// Package grpcError maps the errors of appError to gRPC status codes.
+// It is apart from appError so that the packages without a gRPC server, such as engine, do not depend on gRPC.
+package grpcError
+
+import (
+	"fmt"
+
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// From converts an error of the codes of appError to a gRPC status error.
+func From(err error) error {
+	var code codes.Code
+	switch failure.CodeOf(err) {
+	case appError.ErrConfigError:
+		code = codes.InvalidArgument
+	case appError.ErrDSLSyntaxError:
+		code = codes.InvalidArgument
+	case appError.ErrDSLGenerationFailed:
+		code = codes.Internal
+	case appError.ErrStoreOperationFailed:
+		code = codes.Internal
+	case appError.ErrRequestParameterInvalid:
+		code = codes.InvalidArgument
+	case appError.ErrAuthenticationFailed:
+		code = codes.Unauthenticated
+	case appError.ErrPermissionDenied:
+		code = codes.PermissionDenied
+	case appError.ErrRequestTooLarge:
+		code = codes.ResourceExhausted
+	case appError.ErrNotFound:
+		code = codes.NotFound
+	case appError.ErrUnavailable:
+		code = codes.Unavailable
+	case appError.ErrConflict:
+		code = codes.Aborted
+	case appError.ErrAlreadyExists:
+		code = codes.AlreadyExists
+	case appError.ErrServerError:
+		code = codes.Internal
+	case appError.ErrRequestForwardFailed:
+		code = codes.Internal
+	default:
+		code = codes.Unknown
+	}
+	return status.Error(code, getGRPCErrorMessage(err))
+}
+
+func getGRPCErrorMessage(err error) string {
+	code := failure.CodeOf(err)
+	message := failure.MessageOf(err)
+	cause := failure.CauseOf(err)
+	ret := fmt.Sprintf("code: %s, message: %s, cause: %s", code, message, cause)
+
+	return ret
+}
@@ -1,9 +1,23 @@
 package authn
 
-import "context"
+import (
+	"context"
+
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+)
+
+type Permission string
+
+const (
+	// PermissionExplain allows a client to request the evaluation trace of a check.
+	PermissionExplain Permission = "explain"
+)
 
 type Authenticator interface {
+	// Authenticate returns the principal of the client. It is empty for anonymous clients.
 	Authenticate(context.Context) (string, error)
+	Authorize(context.Context, Permission) error
 }
 
 type NoopAuthenticator struct{}
@@ -11,3 +25,8 @@ type NoopAuthenticator struct{}
 func (a *NoopAuthenticator) Authenticate(ctx context.Context) (string, error) {
 	return "", nil
 }
+
+// Without authentication every client is public, so no permission is granted.
+func (a *NoopAuthenticator) Authorize(ctx context.Context, permission Permission) error {
+	return failure.New(appError.ErrPermissionDenied, failure.Messagef("permission %s requires authentication", permission))
+}
@@ -8,12 +8,18 @@ import (
 	"github.com/shibukazu/open-ve/go/pkg/appError"
 )
 
+const (
+	PrincipalPreshared           = "preshared"
+	PrincipalPresharedPrivileged = "preshared:privileged"
+)
+
 type PresharedKeyAuthenticator struct {
-	key string
+	key           string
+	privilegedKey string
 }
 
-func NewPresharedKeyAuthenticator(key string) *PresharedKeyAuthenticator {
-	return &PresharedKeyAuthenticator{key: key}
+func NewPresharedKeyAuthenticator(key string, privilegedKey string) *PresharedKeyAuthenticator {
+	return &PresharedKeyAuthenticator{key: key, privilegedKey: privilegedKey}
 }
 
 func (a *PresharedKeyAuthenticator) Authenticate(ctx context.Context) (string, error) {
@@ -22,9 +28,30 @@ func (a *PresharedKeyAuthenticator) Authenticate(ctx context.Context) (string, e
 		return "", failure.Translate(err, appError.ErrAuthenticationFailed, failure.Messagef("failed to get auth header"))
 	}
 
+	if a.isPrivileged(authHeader) {
+		return PrincipalPresharedPrivileged, nil
+	}
 	if authHeader != a.key {
 		return "", failure.New(appError.ErrAuthenticationFailed, failure.Messagef("invalid key"))
 	}
 
-	return "", nil
+	return PrincipalPreshared, nil
+}
+
+// Only the privileged key is granted permissions.
+func (a *PresharedKeyAuthenticator) Authorize(ctx context.Context, permission Permission) error {
+	authHeader, err := grpcauth.AuthFromMD(ctx, "Bearer")
+	if err != nil {
+		return failure.Translate(err, appError.ErrAuthenticationFailed, failure.Messagef("failed to get auth header"))
+	}
+
+	if !a.isPrivileged(authHeader) {
+		return failure.New(appError.ErrPermissionDenied, failure.Messagef("permission %s is not granted", permission))
+	}
+
+	return nil
+}
+
+func (a *PresharedKeyAuthenticator) isPrivileged(key string) bool {
+	return a.privilegedKey != "" && key == a.privilegedKey
 }
@@ -0,0 +1,16 @@
+package authn
+
+import "context"
+
+type principalKey struct{}
+
+// WithPrincipal returns a context holding the principal returned by Authenticate.
+func WithPrincipal(ctx context.Context, principal string) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the authenticated principal, or an empty string for anonymous clients.
+func PrincipalFromContext(ctx context.Context) string {
+	principal, _ := ctx.Value(principalKey{}).(string)
+	return principal
+}
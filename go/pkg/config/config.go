@@ -1,13 +1,16 @@
 package config
 
+import "time"
+
 type Config struct {
-	Mode  string      `yaml:"mode"`
-	Slave SlaveConfig `yaml:"slave"`
-	Http  HttpConfig  `yaml:"http"`
-	GRPC  GRPCConfig  `yaml:"grpc"`
-	Store StoreConfig `yaml:"store"`
-	Log   LogConfig   `yaml:"log"`
-	Authn AuthnConfig `yaml:"authn"`
+	Mode   string       `yaml:"mode"`
+	Slave  SlaveConfig  `yaml:"slave"`
+	Http   HttpConfig   `yaml:"http"`
+	GRPC   GRPCConfig   `yaml:"grpc"`
+	Store  StoreConfig  `yaml:"store"`
+	Log    LogConfig    `yaml:"log"`
+	Authn  AuthnConfig  `yaml:"authn"`
+	Bundle BundleConfig `yaml:"bundle"`
 }
 
 type SlaveConfig struct {
@@ -22,23 +25,54 @@ type HttpConfig struct {
 	CORSAllowedOrigins []string  `yaml:"corsAllowedOrigins"`
 	CORSAllowedHeaders []string  `yaml:"corsAllowedHeaders"`
 	TLS                TLSConfig `yaml:"tls"`
+	// FormMaxPartSize is the maximum size in bytes of each field or file of a form check request.
+	FormMaxPartSize int64 `yaml:"formMaxPartSize"`
 }
 
 type GRPCConfig struct {
-	Port string    `yaml:"port"`
-	TLS  TLSConfig `yaml:"tls"`
+	Port              string    `yaml:"port"`
+	TLS               TLSConfig `yaml:"tls"`
+	StreamConcurrency int       `yaml:"streamConcurrency"`
+	// ContextMetadata are the gRPC metadata keys (or HTTP headers) exposed to rules as request.metadata.
+	ContextMetadata []string `yaml:"contextMetadata"`
 }
 
 type RedisConfig struct {
-	Addr     string `yaml:"addr"`
-	Password string `yaml:"password" json:"-"`
-	DB       int    `yaml:"db"`
-	PoolSize int    `yaml:"poolSize"`
+	// Mode is standalone, sentinel or cluster.
+	Mode string `yaml:"mode"`
+	Addr string `yaml:"addr"`
+	// Addrs are the addresses of the sentinels or the cluster nodes. Addr is used if empty.
+	Addrs []string `yaml:"addrs"`
+	// MasterName is the name of the master monitored by the sentinels.
+	MasterName string `yaml:"masterName"`
+	// Username is the ACL user. The default user is used if empty.
+	Username string         `yaml:"username"`
+	Password string         `yaml:"password" json:"-"`
+	DB       int            `yaml:"db"`
+	PoolSize int            `yaml:"poolSize"`
+	TLS      RedisTLSConfig `yaml:"tls"`
+}
+
+type RedisTLSConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// CAPath is the CA certificate the server certificate is verified with. The system roots are used if empty.
+	CAPath string `yaml:"caPath"`
+	// CertPath and KeyPath are the client certificate, required if the server verifies clients.
+	CertPath string `yaml:"certPath"`
+	KeyPath  string `yaml:"keyPath"`
+}
+
+type BoltConfig struct {
+	// Path is the file the DSL is kept in. Only one process can open it at a time.
+	Path string `yaml:"path"`
 }
 
 type StoreConfig struct {
 	Engine string      `yaml:"engine"`
 	Redis  RedisConfig `yaml:"redis"`
+	Bolt   BoltConfig  `yaml:"bolt"`
+	// ChangeCheckInterval is the interval the version is checked at, in case change notifications are lost.
+	ChangeCheckInterval time.Duration `yaml:"changeCheckInterval"`
 }
 
 type LogConfig struct {
@@ -56,8 +90,14 @@ type AuthnConfig struct {
 	Preshared PresharedConfig `yaml:"preshared"`
 }
 
+type BundleConfig struct {
+	// SigningKeyPath is a PEM encoded PKCS #8 Ed25519 private key the compiled bundles are signed with.
+	SigningKeyPath string `yaml:"signingKeyPath"`
+}
+
 type PresharedConfig struct {
-	Key string `yaml:"key"`
+	Key           string `yaml:"key"`
+	PrivilegedKey string `yaml:"privilegedKey"`
 }
 
 func DefaultConfig() *Config {
@@ -81,21 +121,35 @@ func DefaultConfig() *Config {
 			TLS: TLSConfig{
 				Enabled: false,
 			},
+			FormMaxPartSize: 10 * 1024 * 1024,
 		},
 		GRPC: GRPCConfig{
 			Port: "9000",
 			TLS: TLSConfig{
 				Enabled: false,
 			},
+			StreamConcurrency: 16,
+			ContextMetadata:   []string{},
 		},
 		Store: StoreConfig{
 			Engine: "memory",
 			Redis: RedisConfig{
-				Addr:     "redis:6379",
-				Password: "",
-				DB:       0,
-				PoolSize: 1000,
+				Mode:       "standalone",
+				Addr:       "redis:6379",
+				Addrs:      []string{},
+				MasterName: "",
+				Username:   "",
+				Password:   "",
+				DB:         0,
+				PoolSize:   1000,
+				TLS: RedisTLSConfig{
+					Enabled: false,
+				},
+			},
+			Bolt: BoltConfig{
+				Path: "open-ve.db",
 			},
+			ChangeCheckInterval: 10 * time.Second,
 		},
 		Log: LogConfig{
 			Level: "info",
@@ -103,8 +157,12 @@ func DefaultConfig() *Config {
 		Authn: AuthnConfig{
 			Method: "none",
 			Preshared: PresharedConfig{
-				Key: "",
+				Key:           "",
+				PrivilegedKey: "",
 			},
 		},
+		Bundle: BundleConfig{
+			SigningKeyPath: "",
+		},
 	}
 }
@@ -0,0 +1,275 @@
+package binding
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/dsl"
+	"github.com/shibukazu/open-ve/go/pkg/dsl/util"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Path points to a value in a JSON document.
+// It is written either as a JSON Pointer (RFC 6901, e.g. /order/items/0/price)
+// or as a JSONPath subset of child and index accessors (e.g. $.order.items[0].price).
+type Path struct {
+	raw    string
+	tokens []string
+}
+
+func (p *Path) String() string {
+	return p.raw
+}
+
+// DefaultPath binds a variable to the top-level field with the same name.
+func DefaultPath(name string) *Path {
+	return &Path{raw: "/" + escapePointerToken(name), tokens: []string{name}}
+}
+
+// VariablePath returns the path bound to the variable.
+func VariablePath(v *dsl.Variable) (*Path, error) {
+	if v.Path == "" {
+		return DefaultPath(v.Name), nil
+	}
+	return Parse(v.Path)
+}
+
+func Parse(raw string) (*Path, error) {
+	if raw == "" || strings.HasPrefix(raw, "/") {
+		tokens, err := parsePointer(raw)
+		if err != nil {
+			return nil, err
+		}
+		return &Path{raw: raw, tokens: tokens}, nil
+	}
+	if strings.HasPrefix(raw, "$") {
+		tokens, err := parseJSONPath(raw)
+		if err != nil {
+			return nil, err
+		}
+		return &Path{raw: raw, tokens: tokens}, nil
+	}
+	return nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("invalid path: %s\nplease specify a JSON Pointer (/a/b) or a JSONPath ($.a.b)", raw))
+}
+
+// Lookup returns the value at the path. The document is the result of decoding JSON into interface{}.
+func (p *Path) Lookup(document interface{}) (interface{}, bool) {
+	current := document
+	for _, token := range p.tokens {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []interface{}:
+			idx, err := strconv.Atoi(token)
+			if err != nil || idx < 0 || idx >= len(node) {
+				return nil, false
+			}
+			current = node[idx]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// Convert converts a decoded JSON value to the Go value CEL expects for the DSL type.
+// Numbers should be decoded as json.Number so that integers are not rounded;
+// float64 is accepted for values that were already decoded as such, e.g. from google.protobuf.Value.
+func Convert(value interface{}, celType string) (interface{}, error) {
+	switch celType {
+	case "int":
+		switch number := value.(type) {
+		case json.Number:
+			i, err := strconv.ParseInt(number.String(), 10, 64)
+			if err != nil {
+				return nil, numberInvalid(number.String(), celType)
+			}
+			return i, nil
+		case float64:
+			if number != math.Trunc(number) || number < math.MinInt64 || number >= math.MaxInt64 {
+				return nil, numberInvalid(strconv.FormatFloat(number, 'g', -1, 64), celType)
+			}
+			return int64(number), nil
+		default:
+			return nil, typeMismatch(value, celType)
+		}
+	case "uint":
+		switch number := value.(type) {
+		case json.Number:
+			u, err := strconv.ParseUint(number.String(), 10, 64)
+			if err != nil {
+				return nil, numberInvalid(number.String(), celType)
+			}
+			return u, nil
+		case float64:
+			if number != math.Trunc(number) || number < 0 || number >= math.MaxUint64 {
+				return nil, numberInvalid(strconv.FormatFloat(number, 'g', -1, 64), celType)
+			}
+			return uint64(number), nil
+		default:
+			return nil, typeMismatch(value, celType)
+		}
+	case "double":
+		switch number := value.(type) {
+		case json.Number:
+			f, err := number.Float64()
+			if err != nil {
+				return nil, numberInvalid(number.String(), celType)
+			}
+			return f, nil
+		case float64:
+			return number, nil
+		default:
+			return nil, typeMismatch(value, celType)
+		}
+	case "bool":
+		b, ok := value.(bool)
+		if !ok {
+			return nil, typeMismatch(value, celType)
+		}
+		return b, nil
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return nil, typeMismatch(value, celType)
+		}
+		return s, nil
+	case "bytes":
+		s, ok := value.(string)
+		if !ok {
+			return nil, typeMismatch(value, celType)
+		}
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("bytes must be base64 encoded"))
+		}
+		return b, nil
+	case util.TypeDecimal:
+		// JSON numbers may already be rounded, so decimals are given as strings
+		s, ok := value.(string)
+		if !ok {
+			return nil, typeMismatch(value, celType)
+		}
+		return util.ParseDecimal(s)
+	default:
+		return nil, failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("unsupported variable type: %s", celType))
+	}
+}
+
+func typeMismatch(value interface{}, celType string) error {
+	return failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("expected %s but got %s", celType, jsonTypeName(value)))
+}
+
+// numberInvalid reports a number out of the range of the type, or a fraction given for an integer type.
+func numberInvalid(number string, celType string) error {
+	return failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("%s can't be represented as %s", number, celType))
+}
+
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64, json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func parsePointer(raw string) ([]string, error) {
+	if raw == "" {
+		return []string{}, nil
+	}
+	parts := strings.Split(raw[1:], "/")
+	tokens := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if strings.Contains(strings.ReplaceAll(strings.ReplaceAll(part, "~0", ""), "~1", ""), "~") {
+			return nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("invalid escape sequence in JSON Pointer: %s", raw))
+		}
+		tokens = append(tokens, strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~"))
+	}
+	return tokens, nil
+}
+
+func escapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func parseJSONPath(raw string) ([]string, error) {
+	tokens := make([]string, 0)
+	rest := raw[1:]
+	for len(rest) > 0 {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			if name == "" || name == "*" || strings.HasPrefix(name, ".") {
+				return nil, unsupportedJSONPath(raw)
+			}
+			tokens = append(tokens, name)
+			rest = rest[end:]
+		case '[':
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return nil, unsupportedJSONPath(raw)
+			}
+			selector := strings.TrimSpace(rest[1:end])
+			if len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0] {
+				tokens = append(tokens, selector[1:len(selector)-1])
+			} else if idx, err := strconv.Atoi(selector); err == nil && idx >= 0 {
+				tokens = append(tokens, selector)
+			} else {
+				return nil, unsupportedJSONPath(raw)
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, unsupportedJSONPath(raw)
+		}
+	}
+	return tokens, nil
+}
+
+func unsupportedJSONPath(raw string) error {
+	return failure.New(appError.ErrDSLSyntaxError, failure.Messagef("unsupported JSONPath: %s\nonly child (.name, ['name']) and index ([0]) selectors are supported", raw))
+}
+
+// ConvertMessage converts a decoded JSON object to a message of the descriptor using the protobuf JSON mapping.
+func ConvertMessage(value interface{}, md protoreflect.MessageDescriptor) (proto.Message, error) {
+	if _, ok := value.(map[string]interface{}); !ok {
+		return nil, typeMismatch(value, string(md.FullName()))
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("failed to encode %s", md.FullName()))
+	}
+	msg := dynamicpb.NewMessage(md)
+	if err := protojson.Unmarshal(encoded, msg); err != nil {
+		return nil, failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("invalid %s: %v", md.FullName(), err))
+	}
+	return msg, nil
+}
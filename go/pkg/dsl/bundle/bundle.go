@@ -0,0 +1,165 @@
+package bundle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	dslPkg "github.com/shibukazu/open-ve/go/pkg/dsl"
+	"github.com/shibukazu/open-ve/go/pkg/store"
+	pb "github.com/shibukazu/open-ve/go/proto/bundle/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// Build pairs each expression of the DSL with its checked AST compiled on registration.
+// The datasets of the DSL should have their contents.
+func Build(dsl *dslPkg.DSL, compiled map[string]*store.CompiledValidation) (*pb.Bundle, error) {
+	b := &pb.Bundle{
+		FormatVersion:     FormatVersion,
+		FileDescriptorSet: dsl.FileDescriptorSet,
+	}
+	for _, validation := range dsl.Validations {
+		c, ok := compiled[validation.ID]
+		if !ok || len(c.AllEncodedAST) != len(validation.Cels) || len(c.Computed) != len(validation.Computed) || len(c.Profiles) != len(validation.Profiles) {
+			return nil, failure.New(appError.ErrServerError, failure.Messagef("compiled validation %s does not match the schema", validation.ID))
+		}
+
+		compiledValidation := &pb.CompiledValidation{
+			Id:   validation.ID,
+			Cels: compiledExpressions(validation.Cels, c.AllEncodedAST),
+		}
+		for _, variable := range validation.Variables {
+			compiledValidation.Variables = append(compiledValidation.Variables, &pb.Variable{
+				Name: variable.Name,
+				Type: variable.Type,
+				Path: variable.Path,
+			})
+		}
+		for j, computed := range c.Computed {
+			compiledValidation.Computed = append(compiledValidation.Computed, &pb.CompiledComputedVariable{
+				Name:       computed.Name,
+				Expression: &pb.CompiledExpression{Cel: validation.Computed[j].Cel, CheckedExpr: computed.EncodedAST},
+			})
+		}
+		for j, profile := range c.Profiles {
+			if len(profile.AllEncodedAST) != len(validation.Profiles[j].Cels) {
+				return nil, failure.New(appError.ErrServerError, failure.Messagef("compiled profile %s of %s does not match the schema", profile.Name, validation.ID))
+			}
+			compiledProfile := &pb.CompiledProfile{
+				Name:     profile.Name,
+				Cels:     compiledExpressions(validation.Profiles[j].Cels, profile.AllEncodedAST),
+				Optional: profile.Optional,
+			}
+			for _, index := range profile.Exclude {
+				compiledProfile.Exclude = append(compiledProfile.Exclude, int32(index))
+			}
+			compiledValidation.Profiles = append(compiledValidation.Profiles, compiledProfile)
+		}
+		b.Validations = append(b.Validations, compiledValidation)
+	}
+	for _, dataset := range dsl.Datasets {
+		b.Datasets = append(b.Datasets, &pb.Dataset{
+			Name:    dataset.Name,
+			Kind:    dataset.Kind,
+			Values:  dataset.Values,
+			Entries: dataset.Entries,
+		})
+	}
+	version, err := version(b)
+	if err != nil {
+		return nil, err
+	}
+	b.Version = version
+	return b, nil
+}
+
+// version hashes the deterministic encoding of the whole bundle, including the checked expressions and the datasets,
+// so that the version changes whenever a client would load different contents.
+func version(b *pb.Bundle) (string, error) {
+	encoded, err := proto.MarshalOptions{Deterministic: true}.Marshal(b)
+	if err != nil {
+		return "", failure.Translate(err, appError.ErrServerError, failure.Messagef("failed to encode bundle"))
+	}
+	hash := sha256.Sum256(encoded)
+	return hex.EncodeToString(hash[:16]), nil
+}
+
+func compiledExpressions(cels []string, allEncodedAST [][]byte) []*pb.CompiledExpression {
+	expressions := make([]*pb.CompiledExpression, len(cels))
+	for i, cel := range cels {
+		expressions[i] = &pb.CompiledExpression{Cel: cel, CheckedExpr: allEncodedAST[i]}
+	}
+	return expressions
+}
+
+// Decode decodes a serialized bundle into the DSL and its compiled validations,
+// which are registered with reader.DSLReader.RegisterCompiled.
+func Decode(data []byte) (*dslPkg.DSL, map[string]*store.CompiledValidation, error) {
+	b := &pb.Bundle{}
+	if err := proto.Unmarshal(data, b); err != nil {
+		return nil, nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to decode bundle"))
+	}
+	if b.FormatVersion > FormatVersion {
+		return nil, nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("unsupported bundle format version: %d", b.FormatVersion))
+	}
+
+	dsl := &dslPkg.DSL{FileDescriptorSet: b.FileDescriptorSet}
+	for _, dataset := range b.Datasets {
+		dsl.Datasets = append(dsl.Datasets, dslPkg.Dataset{
+			Name:    dataset.Name,
+			Kind:    dataset.Kind,
+			Values:  dataset.Values,
+			Entries: dataset.Entries,
+		})
+	}
+
+	compiled := make(map[string]*store.CompiledValidation, len(b.Validations))
+	for _, v := range b.Validations {
+		validation := dslPkg.Validation{ID: v.Id, Cels: make([]string, len(v.Cels))}
+		c := &store.CompiledValidation{
+			AllEncodedAST: make([][]byte, len(v.Cels)),
+			Computed:      make([]store.EncodedComputedVariable, 0, len(v.Computed)),
+			Profiles:      make([]store.EncodedProfile, 0, len(v.Profiles)),
+		}
+		for _, variable := range v.Variables {
+			validation.Variables = append(validation.Variables, dslPkg.Variable{
+				Name: variable.Name,
+				Type: variable.Type,
+				Path: variable.Path,
+			})
+		}
+		c.Variables = validation.Variables
+		for i, expression := range v.Cels {
+			validation.Cels[i] = expression.Cel
+			c.AllEncodedAST[i] = expression.CheckedExpr
+		}
+		for _, computed := range v.Computed {
+			if computed.Expression == nil {
+				return nil, nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("computed variable %s of %s has no expression", computed.Name, v.Id))
+			}
+			validation.Computed = append(validation.Computed, dslPkg.ComputedVariable{Name: computed.Name, Cel: computed.Expression.Cel})
+			c.Computed = append(c.Computed, store.EncodedComputedVariable{Name: computed.Name, EncodedAST: computed.Expression.CheckedExpr})
+		}
+		for _, profile := range v.Profiles {
+			p := dslPkg.Profile{Name: profile.Name, Optional: profile.Optional}
+			encoded := store.EncodedProfile{Name: profile.Name, Optional: profile.Optional}
+			for _, expression := range profile.Cels {
+				p.Cels = append(p.Cels, expression.Cel)
+				encoded.AllEncodedAST = append(encoded.AllEncodedAST, expression.CheckedExpr)
+			}
+			for _, index := range profile.Exclude {
+				if index < 0 || int(index) >= len(validation.Cels) {
+					return nil, nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("profile %s of %s excludes rule %d out of range", profile.Name, v.Id, index))
+				}
+				p.Exclude = append(p.Exclude, validation.Cels[index])
+				encoded.Exclude = append(encoded.Exclude, int(index))
+			}
+			validation.Profiles = append(validation.Profiles, p)
+			c.Profiles = append(c.Profiles, encoded)
+		}
+		dsl.Validations = append(dsl.Validations, validation)
+		compiled[v.Id] = c
+	}
+	return dsl, compiled, nil
+}
@@ -0,0 +1,69 @@
+package bundle
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"os"
+
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+)
+
+// FormatVersion is the version of the bundle format, incremented on incompatible changes.
+const FormatVersion = 1
+
+// Signer signs bundles with an Ed25519 private key so that clients can verify them with the public key.
+type Signer struct {
+	privateKey ed25519.PrivateKey
+	keyID      string
+}
+
+func NewSigner(privateKey ed25519.PrivateKey) *Signer {
+	return &Signer{privateKey: privateKey, keyID: KeyID(privateKey.Public().(ed25519.PublicKey))}
+}
+
+// LoadSigner reads a PEM encoded PKCS #8 Ed25519 private key,
+// e.g. one generated by openssl genpkey -algorithm ed25519.
+func LoadSigner(path string) (*Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, failure.Translate(err, appError.ErrConfigError, failure.Messagef("failed to read bundle signing key"))
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, failure.New(appError.ErrConfigError, failure.Messagef("bundle signing key is not PEM encoded"))
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, failure.Translate(err, appError.ErrConfigError, failure.Messagef("failed to parse bundle signing key"))
+	}
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, failure.New(appError.ErrConfigError, failure.Messagef("bundle signing key must be an Ed25519 key"))
+	}
+	return NewSigner(privateKey), nil
+}
+
+// Sign returns the signature of the serialized bundle.
+func (s *Signer) Sign(bundle []byte) []byte {
+	return ed25519.Sign(s.privateKey, bundle)
+}
+
+// KeyID identifies the key the bundles are signed with.
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// KeyID returns the first 8 bytes of the SHA-256 hash of the public key in hex.
+func KeyID(publicKey ed25519.PublicKey) string {
+	hash := sha256.Sum256(publicKey)
+	return hex.EncodeToString(hash[:8])
+}
+
+// Verify reports whether the signature of the serialized bundle is valid for the public key.
+func Verify(publicKey ed25519.PublicKey, bundle []byte, signature []byte) bool {
+	return ed25519.Verify(publicKey, bundle, signature)
+}
@@ -3,6 +3,8 @@ package dsl
 type Variable struct {
 	Name string `yaml:"name" json:"name"`
 	Type string `yaml:"type" json:"type"`
+	// Path binds the variable to a value in a JSON document (JSON Pointer or JSONPath).
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
 }
 
 type TestVeriable struct {
@@ -11,18 +13,71 @@ type TestVeriable struct {
 }
 
 type TestCase struct {
-	Name      string         `yaml:"name" json:"name"`
+	Name string `yaml:"name" json:"name"`
+	// Profile is the profile the test case is evaluated with. The rules of the validation are used if empty.
+	Profile   string         `yaml:"profile,omitempty" json:"profile,omitempty"`
 	Variables []TestVeriable `yaml:"variables" json:"variables"`
 	Expected  bool           `yaml:"expected" json:"expected"`
 }
 
+// ComputedVariable is a variable defined by a CEL expression over the variables and the preceding computed variables.
+type ComputedVariable struct {
+	Name string `yaml:"name" json:"name"`
+	Cel  string `yaml:"cel" json:"cel"`
+}
+
+// Profile adjusts the rules of a validation for an operation such as create or update.
+type Profile struct {
+	Name string `yaml:"name" json:"name"`
+	// Cels are the rules evaluated in addition to those of the validation.
+	Cels []string `yaml:"cels,omitempty" json:"cels,omitempty"`
+	// Exclude are the rules of the validation not evaluated, written exactly as in its cels.
+	Exclude []string `yaml:"exclude,omitempty" json:"exclude,omitempty"`
+	// Optional are the variables that may be omitted. Rules referring to an omitted one are not evaluated.
+	Optional []string `yaml:"optional,omitempty" json:"optional,omitempty"`
+}
+
 type Validation struct {
-	ID        string     `yaml:"id" json:"id"`
-	Cels      []string   `yaml:"cels" json:"cels"`
-	Variables []Variable `yaml:"variables" json:"variables"`
-	TestCases []TestCase `yaml:"testCases" json:"testCases"`
+	ID        string             `yaml:"id" json:"id"`
+	Cels      []string           `yaml:"cels" json:"cels"`
+	Variables []Variable         `yaml:"variables" json:"variables"`
+	Computed  []ComputedVariable `yaml:"computed,omitempty" json:"computed,omitempty"`
+	Profiles  []Profile          `yaml:"profiles,omitempty" json:"profiles,omitempty"`
+	TestCases []TestCase         `yaml:"testCases" json:"testCases"`
+}
+
+const (
+	DatasetKindSet = "set"
+	DatasetKindMap = "map"
+)
+
+// Dataset is reference data rules look up by name.
+// The contents are stored apart from the schema and replaced with it.
+type Dataset struct {
+	Name string `yaml:"name" json:"name"`
+	// Kind is either set or map.
+	Kind string `yaml:"kind" json:"kind"`
+	// Values are the members of a set.
+	Values []string `yaml:"values,omitempty" json:"values,omitempty"`
+	// Entries are the key/value pairs of a map.
+	Entries map[string]string `yaml:"entries,omitempty" json:"entries,omitempty"`
+	// File is a YAML or JSON file the contents are loaded from, relative to the DSL file.
+	// It holds a list of values for a set and a mapping for a map.
+	File string `yaml:"file,omitempty" json:"-"`
+	// Hash is the hash of the contents, set in the registered schema so that its version is computed
+	// without reading the contents.
+	Hash string `yaml:"-" json:"-"`
 }
 
 type DSL struct {
 	Validations []Validation `yaml:"validations" json:"validations"`
+	Datasets    []Dataset    `yaml:"datasets,omitempty" json:"datasets,omitempty"`
+	// FileDescriptorSet is a serialized google.protobuf.FileDescriptorSet including imports.
+	// Its message types can be used as variable types.
+	FileDescriptorSet []byte `yaml:"-" json:"fileDescriptorSet,omitempty"`
+	// FileDescriptorSetPath is the file FileDescriptorSet is loaded from, relative to the DSL file.
+	FileDescriptorSetPath string `yaml:"fileDescriptorSetPath,omitempty" json:"-"`
+	// Version is the version of the registered schema, computed when it is registered.
+	// A DSL exported by open-ve dsl export has the version it was exported with, which is ignored on registration.
+	Version string `yaml:"version,omitempty" json:"-"`
 }
@@ -3,110 +3,565 @@ package reader
 import (
 	"context"
 	"log/slog"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/google/cel-go/cel"
 	"github.com/morikuni/failure/v2"
 	"github.com/shibukazu/open-ve/go/pkg/appError"
 	dslPkg "github.com/shibukazu/open-ve/go/pkg/dsl"
+	"github.com/shibukazu/open-ve/go/pkg/dsl/binding"
 	"github.com/shibukazu/open-ve/go/pkg/dsl/util"
+	"github.com/shibukazu/open-ve/go/pkg/logger"
 	"github.com/shibukazu/open-ve/go/pkg/store"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
 )
 
 type DSLReader struct {
 	store  store.Store
 	logger *slog.Logger
+	// changed is closed and replaced whenever a DSL or a dataset is registered, by this or another process sharing the store.
+	changed chan struct{}
+	// files caches the parsed file descriptor set until the next change, so that it is not parsed on every lookup.
+	files       *protoregistry.Files
+	filesLoaded bool
+	// ids caches the validation IDs of the registered DSL until the next change, so that the schema is not decoded
+	// to find whether a validation exists.
+	ids map[string]struct{}
+	mu  sync.Mutex
+	// registerMu serializes the registrations of this process, since registering a dataset rewrites the schema
+	// with its version.
+	registerMu sync.Mutex
 }
 
 func NewDSLReader(logger *slog.Logger, store store.Store) *DSLReader {
-	return &DSLReader{logger: logger, store: store}
+	return &DSLReader{logger: logger, store: store, changed: make(chan struct{})}
+}
+
+// Changed returns a channel closed when a DSL or a dataset is registered next.
+// Registrations by other processes sharing the store are only seen while WatchStore runs.
+// It should be obtained before reading the DSL so that no registration in between is missed.
+func (r *DSLReader) Changed() <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.changed
+}
+
+func (r *DSLReader) closeChanged() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	close(r.changed)
+	r.changed = make(chan struct{})
+	r.files, r.filesLoaded = nil, false
+	r.ids = nil
+}
+
+// notifyChanged tells the watchers of this process, and those of other processes through the store.
+// A failure to notify is only logged since the others find the change by the version check of WatchStore.
+func (r *DSLReader) notifyChanged(ctx context.Context) {
+	r.closeChanged()
+	// The registration is done even if the request is canceled now
+	if err := r.store.Notify(context.WithoutCancel(ctx)); err != nil {
+		logger.LogError(r.logger, err)
+	}
+}
+
+// WatchStore closes the channel of Changed when another process sharing the store registers a DSL or a dataset.
+// The notifications of the store may be lost while disconnected, so the version is also checked at the interval
+// unless it is zero.
+func (r *DSLReader) WatchStore(ctx context.Context, wg *sync.WaitGroup, interval time.Duration) {
+	defer wg.Done()
+	r.logger.Info("🟢 store watcher started")
+	version, err := r.store.ReadVersion(ctx)
+	if err != nil {
+		logger.LogError(r.logger, err)
+	}
+	versions, err := r.store.Subscribe(ctx)
+	if err != nil {
+		logger.LogError(r.logger, err)
+	}
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Info("🛑 store watcher stopped")
+			return
+		case v, ok := <-versions:
+			if !ok {
+				// Subscribed again on the next check
+				versions = nil
+				continue
+			}
+			if v != version {
+				version = v
+				r.closeChanged()
+			}
+		case <-tick:
+			if versions == nil {
+				if versions, err = r.store.Subscribe(ctx); err != nil {
+					logger.LogError(r.logger, err)
+				}
+			}
+			current, err := r.store.ReadVersion(ctx)
+			if err != nil {
+				logger.LogError(r.logger, err)
+				continue
+			}
+			if current != version {
+				r.logger.Info("🔄 store version changed", slog.String("version", current))
+				version = current
+				r.closeChanged()
+			}
+		}
+	}
 }
 
 func (r *DSLReader) Read(ctx context.Context) (*dslPkg.DSL, error) {
-	dsl, err := r.store.ReadSchema()
+	dsl, err := r.store.ReadSchema(ctx)
 	if err != nil {
 		return nil, err
 	}
+	// The schemas registered before the version was kept have none, so it is computed from the contents
+	if dsl.Version == "" {
+		if err := hashDatasets(ctx, r.store, dsl); err != nil {
+			return nil, err
+		}
+		if dsl.Version, err = store.SchemaVersion(dsl); err != nil {
+			return nil, err
+		}
+	}
 	return dsl, nil
 }
 
+// hashDatasets sets the hashes of the datasets of the schema that have none from their contents.
+func hashDatasets(ctx context.Context, s store.Store, schema *dslPkg.DSL) error {
+	for i := range schema.Datasets {
+		if schema.Datasets[i].Hash != "" {
+			continue
+		}
+		entries, err := s.ReadDataset(ctx, schema.Datasets[i].Name)
+		if err != nil {
+			return err
+		}
+		schema.Datasets[i].Hash = store.DatasetHash(entries)
+	}
+	return nil
+}
+
+// ReadFileDescriptorSet returns the registered file descriptor set, or nil if none is registered.
+// The set is parsed once per registration and cached until Changed is closed, so registrations by other processes
+// sharing the store are only seen while WatchStore runs.
+func (r *DSLReader) ReadFileDescriptorSet(ctx context.Context) (*protoregistry.Files, error) {
+	r.mu.Lock()
+	if r.filesLoaded {
+		defer r.mu.Unlock()
+		return r.files, nil
+	}
+	changed := r.changed
+	r.mu.Unlock()
+
+	fileDescriptorSet, err := r.store.ReadFileDescriptorSet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	files, err := util.ParseFileDescriptorSet(fileDescriptorSet)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Not cached if a registration happened meanwhile, since the set read may be the previous one
+	if r.changed == changed {
+		r.files, r.filesLoaded = files, true
+	}
+	return files, nil
+}
+
+// HasValidation reports whether the registered DSL has the validation ID. It is false while no DSL is registered.
+// The IDs are read once per registration and cached until Changed is closed, in the same way as ReadFileDescriptorSet.
+func (r *DSLReader) HasValidation(ctx context.Context, id string) (bool, error) {
+	r.mu.Lock()
+	if r.ids != nil {
+		defer r.mu.Unlock()
+		_, ok := r.ids[id]
+		return ok, nil
+	}
+	changed := r.changed
+	r.mu.Unlock()
+
+	ids := make(map[string]struct{})
+	dsl, err := r.store.ReadSchema(ctx)
+	switch {
+	case failure.Is(err, appError.ErrNotFound):
+	case err != nil:
+		return false, err
+	default:
+		for _, validation := range dsl.Validations {
+			ids[validation.ID] = struct{}{}
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Not cached if a registration happened meanwhile, since the schema read may be the previous one
+	if r.changed == changed {
+		r.ids = ids
+	}
+	_, ok := ids[id]
+	return ok, nil
+}
+
+// Register compiles the DSL and replaces the registered one with it.
+// The DSL is compiled before the store is written, so that an invalid DSL keeps the registered one in any store.
 func (r *DSLReader) Register(ctx context.Context, dsl *dslPkg.DSL) error {
-	if err := r.store.Reset(); err != nil {
+	compiled, err := compileDSL(dsl)
+	if err != nil {
 		return err
 	}
-	if err := r.parseAndSaveDSL(dsl); err != nil {
+	return r.RegisterCompiled(ctx, dsl, compiled)
+}
+
+// transaction runs fn in a transaction if the store supports it, so that a failed registration keeps the previous DSL.
+func (r *DSLReader) transaction(ctx context.Context, fn func(store.Store) error) error {
+	if transactor, ok := r.store.(store.Transactor); ok {
+		return transactor.Transaction(ctx, fn)
+	}
+	return fn(r.store)
+}
+
+// RegisterDataset replaces the contents of a dataset declared in the schema and updates the version of the schema.
+func (r *DSLReader) RegisterDataset(ctx context.Context, dataset *dslPkg.Dataset) error {
+	r.registerMu.Lock()
+	defer r.registerMu.Unlock()
+
+	declared, err := r.findDataset(ctx, dataset.Name)
+	if err != nil {
 		return err
 	}
+	if dataset.Kind == "" {
+		dataset.Kind = declared.Kind
+	}
+	if dataset.Kind != declared.Kind {
+		return failure.New(appError.ErrDSLSyntaxError, failure.Messagef("dataset %s is declared as %s", dataset.Name, declared.Kind))
+	}
+	entries, err := util.DatasetEntries(dataset)
+	if err != nil {
+		return err
+	}
+	if err := r.transaction(ctx, func(s store.Store) error {
+		schema, err := s.ReadSchema(ctx)
+		if err != nil {
+			return err
+		}
+		for i := range schema.Datasets {
+			if schema.Datasets[i].Name == dataset.Name {
+				schema.Datasets[i].Hash = store.DatasetHash(entries)
+			}
+		}
+		if err := hashDatasets(ctx, s, schema); err != nil {
+			return err
+		}
+		if schema.Version, err = store.SchemaVersion(schema); err != nil {
+			return err
+		}
+		if err := s.WriteDataset(ctx, dataset.Name, entries); err != nil {
+			return err
+		}
+		return s.WriteSchema(ctx, schema)
+	}); err != nil {
+		return err
+	}
+	r.notifyChanged(ctx)
 	return nil
 }
 
-func (r *DSLReader) GetVariableNameToCELType(ctx context.Context, id string) (map[string]string, error) {
+// ReadWithDatasets returns the DSL with the contents of its datasets, which Read leaves out.
+func (r *DSLReader) ReadWithDatasets(ctx context.Context) (*dslPkg.DSL, error) {
 	dsl, err := r.Read(ctx)
 	if err != nil {
 		return nil, err
 	}
+	for i := range dsl.Datasets {
+		dataset, err := r.readDatasetContents(ctx, &dsl.Datasets[i])
+		if err != nil {
+			return nil, err
+		}
+		dsl.Datasets[i] = *dataset
+	}
+	return dsl, nil
+}
 
-	validation := dslPkg.Validation{}
-	for _, v := range dsl.Validations {
-		if v.ID == id {
-			validation = v
-			break
+func (r *DSLReader) ReadDataset(ctx context.Context, name string) (*dslPkg.Dataset, error) {
+	declared, err := r.findDataset(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return r.readDatasetContents(ctx, declared)
+}
+
+func (r *DSLReader) readDatasetContents(ctx context.Context, declared *dslPkg.Dataset) (*dslPkg.Dataset, error) {
+	entries, err := r.store.ReadDataset(ctx, declared.Name)
+	if err != nil {
+		return nil, err
+	}
+	dataset := &dslPkg.Dataset{Name: declared.Name, Kind: declared.Kind, Hash: declared.Hash}
+	switch declared.Kind {
+	case dslPkg.DatasetKindSet:
+		dataset.Values = make([]string, 0, len(entries))
+		for value := range entries {
+			dataset.Values = append(dataset.Values, value)
+		}
+		sort.Strings(dataset.Values)
+	default:
+		dataset.Entries = entries
+	}
+	return dataset, nil
+}
+
+func (r *DSLReader) findDataset(ctx context.Context, name string) (*dslPkg.Dataset, error) {
+	dsl, err := r.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range dsl.Datasets {
+		if d.Name == name {
+			return &d, nil
 		}
 	}
-	variables := validation.Variables
+	return nil, failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("dataset %s is not declared", name))
+}
 
-	var variableNameToCELType = make(map[string]string)
-	for _, v := range variables {
+func (r *DSLReader) ReadCompiledValidation(ctx context.Context, id string) (*store.CompiledValidation, error) {
+	return r.store.ReadCompiledValidation(ctx, id)
+}
+
+func (r *DSLReader) GetVariableNameToCELType(ctx context.Context, id string) (map[string]string, error) {
+	compiled, err := r.store.ReadCompiledValidation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	variableNameToCELType := make(map[string]string, len(compiled.Variables))
+	for _, v := range compiled.Variables {
 		variableNameToCELType[v.Name] = v.Type
 	}
 	return variableNameToCELType, nil
 }
 
-func (r *DSLReader) parseAndSaveDSL(dsl *dslPkg.DSL) error {
-	// TODO: make following operations atomic
-	// Save DSL to Store
-	if err := r.store.WriteSchema(dsl); err != nil {
+// RegisterCompiled registers the DSL with the checked ASTs compiled beforehand, e.g. those of a bundle,
+// without compiling the expressions again. compiled has an entry per validation ID.
+// The watchers are notified only if the registration succeeds.
+func (r *DSLReader) RegisterCompiled(ctx context.Context, dsl *dslPkg.DSL, compiled map[string]*store.CompiledValidation) error {
+	r.registerMu.Lock()
+	defer r.registerMu.Unlock()
+	if err := checkDatasets(dsl); err != nil {
 		return err
 	}
 	for _, v := range dsl.Validations {
-		// Save Variables to Store
-		if err := r.store.WriteVariables(v.ID, v.Variables); err != nil {
+		if _, ok := compiled[v.ID]; !ok {
+			return failure.New(appError.ErrDSLSyntaxError, failure.Messagef("validation %s is not compiled", v.ID))
+		}
+	}
+	if err := r.transaction(ctx, func(s store.Store) error {
+		if err := s.Reset(ctx); err != nil {
 			return err
 		}
+		return r.saveCompiled(ctx, s, dsl, compiled)
+	}); err != nil {
+		return err
+	}
+	r.notifyChanged(ctx)
+	return nil
+}
 
-		celVariables, err := util.DSLVariablesToCELVariables(v.Variables)
-		if err != nil {
+func (r *DSLReader) saveCompiled(ctx context.Context, s store.Store, dsl *dslPkg.DSL, compiled map[string]*store.CompiledValidation) error {
+	if err := r.saveSchema(ctx, s, dsl); err != nil {
+		return err
+	}
+	for _, v := range dsl.Validations {
+		if err := s.WriteCompiledValidation(ctx, v.ID, compiled[v.ID]); err != nil {
 			return err
 		}
-		env, err := cel.NewEnv(celVariables...)
-		if err != nil {
-			return failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to create CEL environment"))
+	}
+	return nil
+}
+
+// checkDatasets checks the declarations and the contents of the datasets of the DSL.
+func checkDatasets(dsl *dslPkg.DSL) error {
+	declared := make(map[string]bool, len(dsl.Datasets))
+	for _, d := range dsl.Datasets {
+		if declared[d.Name] {
+			return failure.New(appError.ErrDSLSyntaxError, failure.Messagef("dataset %s is already declared", d.Name))
 		}
+		declared[d.Name] = true
+		if _, err := util.DatasetEntries(&d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-		allEncodedAST := make([][]byte, 0, len(v.Cels))
-		for _, inputCel := range v.Cels {
-			ast, issues := env.Compile(inputCel)
-			if issues != nil && issues.Err() != nil {
-				return failure.Translate(issues.Err(), appError.ErrDSLSyntaxError, failure.Messagef("failed to compile CEL"))
+// compileDSL compiles the validations of the DSL without writing the store.
+func compileDSL(dsl *dslPkg.DSL) (map[string]*store.CompiledValidation, error) {
+	files, err := util.ParseFileDescriptorSet(dsl.FileDescriptorSet)
+	if err != nil {
+		return nil, err
+	}
+
+	compileTime := time.Now()
+	compiledValidations := make(map[string]*store.CompiledValidation, len(dsl.Validations))
+	for _, v := range dsl.Validations {
+		for _, variable := range v.Variables {
+			if _, err := binding.VariablePath(&variable); err != nil {
+				return nil, err
 			}
+		}
 
-			// Convert AST to Proto
-			expr, err := cel.AstToCheckedExpr(ast)
+		env, err := util.NewCELEnv(v.Variables, files, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		// Compile Computed Variables
+		env, computedASTs, err := util.CompileComputedVariables(env, v.Variables, v.Computed)
+		if err != nil {
+			return nil, err
+		}
+		computedVariables := make([]store.EncodedComputedVariable, 0, len(v.Computed))
+		for i, computed := range v.Computed {
+			if err := util.CheckDatasetReferences(computedASTs[i], dsl.Datasets); err != nil {
+				return nil, failure.Wrap(err, failure.Messagef("invalid computed variable: %s", computed.Name))
+			}
+			if err := util.CheckSignatureFormats(computedASTs[i]); err != nil {
+				return nil, failure.Wrap(err, failure.Messagef("invalid computed variable: %s", computed.Name))
+			}
+			encodedAST, err := encodeAST(computedASTs[i])
 			if err != nil {
-				return failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to convert AST to Proto"))
+				return nil, err
 			}
-			encodedAST, err := proto.Marshal(expr)
+			computedVariables = append(computedVariables, store.EncodedComputedVariable{Name: computed.Name, EncodedAST: encodedAST})
+		}
+
+		// Compile Rules
+		allEncodedAST, err := compileRules(env, v.Cels, dsl.Datasets)
+		if err != nil {
+			return nil, err
+		}
+
+		// Compile Profiles
+		excludes, err := util.CheckProfiles(&v)
+		if err != nil {
+			return nil, err
+		}
+		profiles := make([]store.EncodedProfile, 0, len(v.Profiles))
+		for i, profile := range v.Profiles {
+			allEncodedAST, err := compileRules(env, profile.Cels, dsl.Datasets)
 			if err != nil {
-				return failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to encode AST"))
+				return nil, failure.Wrap(err, failure.Messagef("invalid profile: %s", profile.Name))
 			}
-			allEncodedAST = append(allEncodedAST, encodedAST)
+			profiles = append(profiles, store.EncodedProfile{
+				Name:          profile.Name,
+				AllEncodedAST: allEncodedAST,
+				Exclude:       excludes[i],
+				Optional:      profile.Optional,
+			})
+		}
+
+		compiledValidations[v.ID] = &store.CompiledValidation{
+			Variables:     v.Variables,
+			AllEncodedAST: allEncodedAST,
+			Computed:      computedVariables,
+			Profiles:      profiles,
+			CompileTime:   compileTime,
+			CELVersion:    celVersion(),
 		}
+	}
+	return compiledValidations, nil
+}
 
-		// Save All Encoded AST to Store
-		if err := r.store.WriteAllEncodedAST(v.ID, allEncodedAST); err != nil {
+// celVersion returns the version of cel-go the binary is built with, kept with the compiled validations
+// since the checked expressions may not be read by other versions.
+var celVersion = sync.OnceValue(func() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == "github.com/google/cel-go" {
+			return dep.Version
+		}
+	}
+	return ""
+})
+
+// saveSchema saves the schema with its version, its file descriptor set and the datasets.
+func (r *DSLReader) saveSchema(ctx context.Context, s store.Store, dsl *dslPkg.DSL) error {
+	// Save Datasets to Store. The schema only keeps their declarations and the hashes of their contents.
+	schema := *dsl
+	schema.Datasets = make([]dslPkg.Dataset, 0, len(dsl.Datasets))
+	for _, d := range dsl.Datasets {
+		entries, err := util.DatasetEntries(&d)
+		if err != nil {
 			return err
 		}
+		if err := s.WriteDataset(ctx, d.Name, entries); err != nil {
+			return err
+		}
+		schema.Datasets = append(schema.Datasets, dslPkg.Dataset{Name: d.Name, Kind: d.Kind, Hash: store.DatasetHash(entries)})
+	}
+	version, err := store.SchemaVersion(&schema)
+	if err != nil {
+		return err
 	}
+	schema.Version = version
 
-	return nil
+	// Save DSL to Store
+	return s.WriteSchema(ctx, &schema)
+}
+
+func compileRules(env *cel.Env, cels []string, datasets []dslPkg.Dataset) ([][]byte, error) {
+	allEncodedAST := make([][]byte, 0, len(cels))
+	for _, inputCel := range cels {
+		ast, issues := env.Compile(inputCel)
+		if issues != nil && issues.Err() != nil {
+			return nil, failure.Translate(issues.Err(), appError.ErrDSLSyntaxError, failure.Messagef("failed to compile CEL"))
+		}
+		if err := util.CheckResultType(ast); err != nil {
+			return nil, failure.Wrap(err, failure.Messagef("invalid rule: %s", inputCel))
+		}
+		if err := util.CheckDatasetReferences(ast, datasets); err != nil {
+			return nil, failure.Wrap(err, failure.Messagef("invalid rule: %s", inputCel))
+		}
+		if err := util.CheckSignatureFormats(ast); err != nil {
+			return nil, failure.Wrap(err, failure.Messagef("invalid rule: %s", inputCel))
+		}
+
+		encodedAST, err := encodeAST(ast)
+		if err != nil {
+			return nil, err
+		}
+		allEncodedAST = append(allEncodedAST, encodedAST)
+	}
+	return allEncodedAST, nil
+}
+
+func encodeAST(ast *cel.Ast) ([]byte, error) {
+	// Convert AST to Proto
+	expr, err := cel.AstToCheckedExpr(ast)
+	if err != nil {
+		return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to convert AST to Proto"))
+	}
+	encodedAST, err := proto.Marshal(expr)
+	if err != nil {
+		return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to encode AST"))
+	}
+	return encodedAST, nil
 }
@@ -1,11 +1,15 @@
 package tester
 
 import (
+	"time"
+
 	"github.com/google/cel-go/cel"
 	"github.com/morikuni/failure/v2"
 	"github.com/shibukazu/open-ve/go/pkg/appError"
 	"github.com/shibukazu/open-ve/go/pkg/dsl"
+	"github.com/shibukazu/open-ve/go/pkg/dsl/binding"
 	"github.com/shibukazu/open-ve/go/pkg/dsl/util"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type Result struct {
@@ -21,6 +25,18 @@ type ValidationResult struct {
 func TestDSL(d *dsl.DSL) (*Result, error) {
 	result := &Result{}
 	result.ValidationResults = make([]ValidationResult, 0)
+	files, err := util.ParseFileDescriptorSet(d.FileDescriptorSet)
+	if err != nil {
+		return nil, err
+	}
+	datasets := make(util.StaticDatasets, len(d.Datasets))
+	for _, dataset := range d.Datasets {
+		entries, err := util.DatasetEntries(&dataset)
+		if err != nil {
+			return nil, err
+		}
+		datasets[dataset.Name] = entries
+	}
 	for _, validation := range d.Validations {
 		if len(validation.TestCases) == 0 {
 			result.ValidationResults = append(result.ValidationResults, ValidationResult{
@@ -31,39 +47,148 @@ func TestDSL(d *dsl.DSL) (*Result, error) {
 			continue
 		}
 		variables := validation.Variables
-		celVariables, err := util.DSLVariablesToCELVariables(variables)
+		env, err := util.NewCELEnv(variables, files, datasets)
 		if err != nil {
 			return nil, err
 		}
-		env, err := cel.NewEnv(celVariables...)
+		env, computedASTs, err := util.CompileComputedVariables(env, variables, validation.Computed)
+		if err != nil {
+			return nil, err
+		}
+		dependencies := make(map[string][]string, len(variables)+len(computedASTs))
+		for _, v := range variables {
+			dependencies[v.Name] = []string{v.Name}
+		}
+		computedPrograms := make([]cel.Program, 0, len(computedASTs))
+		for i, ast := range computedASTs {
+			dependencies[validation.Computed[i].Name] = util.ReferencedVariables(ast, dependencies)
+			if err := util.CheckDatasetReferences(ast, d.Datasets); err != nil {
+				return nil, failure.Wrap(err, failure.Messagef("invalid computed variable: %s", validation.Computed[i].Name))
+			}
+			if err := util.CheckSignatureFormats(ast); err != nil {
+				return nil, failure.Wrap(err, failure.Messagef("invalid computed variable: %s", validation.Computed[i].Name))
+			}
+			prg, err := env.Program(ast)
+			if err != nil {
+				return nil, failure.Translate(err, failure.Messagef("failed to create program of computed variable %s: %v", validation.Computed[i].Name, err))
+			}
+			computedPrograms = append(computedPrograms, prg)
+		}
+		variableTypes := make(map[string]string, len(variables))
+		for _, v := range variables {
+			variableTypes[v.Name] = v.Type
+		}
+		excludes, err := util.CheckProfiles(&validation)
 		if err != nil {
-			return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to create CEL environment: %v", err))
+			return nil, err
 		}
-		cels := validation.Cels
 
 		failedTestCases := make([]string, 0)
 		for _, testCase := range validation.TestCases {
+			cels := validation.Cels
+			optional := make(map[string]bool)
+			profile, err := util.FindProfile(&validation, testCase.Profile)
+			if err != nil {
+				return nil, failure.Wrap(err, failure.Messagef("invalid test case %s", testCase.Name))
+			}
+			if profile != nil {
+				excluded := make(map[int]bool)
+				for _, index := range excludes[profileIndex(&validation, profile.Name)] {
+					excluded[index] = true
+				}
+				cels = make([]string, 0, len(validation.Cels)+len(profile.Cels))
+				for i, cel := range validation.Cels {
+					if !excluded[i] {
+						cels = append(cels, cel)
+					}
+				}
+				cels = append(cels, profile.Cels...)
+				for _, name := range profile.Optional {
+					optional[name] = true
+				}
+			}
+
+			inputVariables := util.ContextVariables("", nil, time.Now())
+			for _, v := range testCase.Variables {
+				inputVariables[v.Name] = v.Value
+				// openve.now can be fixed by test cases with an RFC3339 string
+				if v.Name == util.ContextVariableNow {
+					now, err := parseNow(v.Value)
+					if err != nil {
+						return nil, failure.Wrap(err, failure.Messagef("invalid value of variable %s in test case %s", v.Name, testCase.Name))
+					}
+					inputVariables[v.Name] = now
+					continue
+				}
+				if variableTypes[v.Name] == util.TypeDecimal {
+					d, err := util.ToDecimal(v.Value)
+					if err != nil {
+						return nil, failure.Wrap(err, failure.Messagef("invalid value of variable %s in test case %s", v.Name, testCase.Name))
+					}
+					inputVariables[v.Name] = d
+					continue
+				}
+				// Message values are written as objects in the protobuf JSON mapping
+				if variableType, ok := variableTypes[v.Name]; ok && !util.IsPrimitiveType(variableType) {
+					md, err := util.FindMessageDescriptor(files, variableType)
+					if err != nil {
+						return nil, err
+					}
+					msg, err := binding.ConvertMessage(v.Value, md)
+					if err != nil {
+						return nil, failure.Wrap(err, failure.Messagef("invalid value of variable %s in test case %s", v.Name, testCase.Name))
+					}
+					inputVariables[v.Name] = msg
+				}
+			}
+			for i, prg := range computedPrograms {
+				if !hasVariables(inputVariables, dependencies[validation.Computed[i].Name]) {
+					continue
+				}
+				val, _, err := prg.Eval(inputVariables)
+				if err != nil {
+					return nil, failure.Translate(err, failure.Messagef("failed to evaluate computed variable %s: %v", validation.Computed[i].Name, err))
+				}
+				inputVariables[validation.Computed[i].Name] = val
+			}
+
 			passAll := true
 			for _, cel := range cels {
 				ast, issues := env.Compile(cel)
 				if issues != nil && issues.Err() != nil {
-					return nil, failure.Translate(err, failure.Messagef("failed to compile CEL: %v", issues.Err()))
+					return nil, failure.Translate(issues.Err(), appError.ErrDSLSyntaxError, failure.Messagef("failed to compile CEL: %v", issues.Err()))
+				}
+				if err := util.CheckResultType(ast); err != nil {
+					return nil, failure.Wrap(err, failure.Messagef("invalid rule: %s", cel))
+				}
+				if err := util.CheckDatasetReferences(ast, d.Datasets); err != nil {
+					return nil, failure.Wrap(err, failure.Messagef("invalid rule: %s", cel))
+				}
+				if err := util.CheckSignatureFormats(ast); err != nil {
+					return nil, failure.Wrap(err, failure.Messagef("invalid rule: %s", cel))
+				}
+				// Rules referring to an omitted optional variable are not evaluated
+				skipped := false
+				for _, name := range util.ReferencedVariables(ast, dependencies) {
+					if _, ok := inputVariables[name]; !ok && optional[name] {
+						skipped = true
+						break
+					}
+				}
+				if skipped {
+					continue
 				}
 				prg, err := env.Program(ast)
 				if err != nil {
 					return nil, failure.Translate(err, failure.Messagef("failed to create program: %v", err))
 				}
-				inputVariables := make(map[string]interface{})
-				for _, v := range testCase.Variables {
-					inputVariables[v.Name] = v.Value
-				}
 				res, _, err := prg.Eval(inputVariables)
 				if err != nil {
 					return nil, failure.Translate(err, failure.Messagef("failed to evaluate program: %v", err))
 				}
-				pass, ok := res.Value().(bool)
-				if !ok {
-					return nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("unsupported result type: %T\nplease specify one of the following types: bool", res.Value()))
+				pass, _, err := util.ParseResult(res)
+				if err != nil {
+					return nil, err
 				}
 				passAll = passAll && pass
 			}
@@ -80,3 +205,36 @@ func TestDSL(d *dsl.DSL) (*Result, error) {
 	}
 	return result, nil
 }
+
+func profileIndex(validation *dsl.Validation, name string) int {
+	for i, profile := range validation.Profiles {
+		if profile.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func hasVariables(variables map[string]interface{}, names []string) bool {
+	for _, name := range names {
+		if _, ok := variables[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func parseNow(value interface{}) (*timestamppb.Timestamp, error) {
+	switch v := value.(type) {
+	case string:
+		now, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("now must be an RFC3339 timestamp"))
+		}
+		return timestamppb.New(now), nil
+	case time.Time:
+		return timestamppb.New(v), nil
+	default:
+		return nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("now must be an RFC3339 timestamp"))
+	}
+}
@@ -0,0 +1,156 @@
+package util
+
+import (
+	"bytes"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"mime"
+	"net/http"
+
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	_ "golang.org/x/image/webp"
+)
+
+// fileSignatures are the leading bytes of the file formats hasSignature accepts by name.
+// WebP is checked separately since its signature has the file size in between.
+var fileSignatures = map[string][][]byte{
+	"png":  {[]byte("\x89PNG\r\n\x1a\n")},
+	"jpeg": {[]byte("\xff\xd8\xff")},
+	"gif":  {[]byte("GIF87a"), []byte("GIF89a")},
+	"pdf":  {[]byte("%PDF-")},
+	"zip":  {[]byte("PK\x03\x04"), []byte("PK\x05\x06")},
+	"gzip": {[]byte("\x1f\x8b")},
+}
+
+const functionHasSignature = "hasSignature"
+
+func hasSignature(data []byte, format string) (bool, bool) {
+	if format == "webp" {
+		return len(data) >= 12 && bytes.HasPrefix(data, []byte("RIFF")) && bytes.Equal(data[8:12], []byte("WEBP")), true
+	}
+	signatures, ok := fileSignatures[format]
+	if !ok {
+		return false, false
+	}
+	for _, signature := range signatures {
+		if bytes.HasPrefix(data, signature) {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+// CheckSignatureFormats checks that the formats hasSignature is given as string literals are known,
+// so that a misspelled format fails the registration instead of every check of the validation.
+// Formats given by other expressions are checked at evaluation.
+func CheckSignatureFormats(ast *cel.Ast) error {
+	calls := celast.MatchDescendants(celast.NavigateAST(ast.NativeRep()), celast.FunctionMatcher(functionHasSignature))
+	for _, call := range calls {
+		args := call.AsCall().Args()
+		if len(args) != 2 || args[1].Kind() != celast.LiteralKind {
+			continue
+		}
+		format, ok := args[1].AsLiteral().(types.String)
+		if !ok {
+			continue
+		}
+		if _, known := hasSignature(nil, string(format)); !known {
+			return failure.New(appError.ErrDSLSyntaxError, failure.Messagef("unknown file signature: %s", format))
+		}
+	}
+	return nil
+}
+
+// mimeType detects the MIME type of the data by its content, without parameters such as charset.
+func mimeType(data []byte) string {
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(data))
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return mediaType
+}
+
+func binaryFunctions() []cel.EnvOption {
+	imageConfig := func(arg ref.Val) (image.Config, ref.Val) {
+		config, _, err := image.DecodeConfig(bytes.NewReader(arg.(types.Bytes)))
+		if err != nil {
+			return image.Config{}, types.NewErr("not a PNG, JPEG, GIF or WebP image: %v", err)
+		}
+		return config, nil
+	}
+	return []cel.EnvOption{
+		// mimeType(<bytes>) -> string
+		// Returns the MIME type detected from the content, e.g. "image/png" or "text/plain".
+		cel.Function("mimeType",
+			cel.Overload("mime_type_bytes", []*cel.Type{cel.BytesType}, cel.StringType,
+				cel.UnaryBinding(func(arg ref.Val) ref.Val {
+					return types.String(mimeType(arg.(types.Bytes)))
+				}),
+			),
+		),
+		// isImage(<bytes>) -> bool
+		// Returns whether the data is a PNG, JPEG, GIF or WebP image whose size can be read.
+		cel.Function("isImage",
+			cel.Overload("is_image_bytes", []*cel.Type{cel.BytesType}, cel.BoolType,
+				cel.UnaryBinding(func(arg ref.Val) ref.Val {
+					_, errVal := imageConfig(arg)
+					return types.Bool(errVal == nil)
+				}),
+			),
+		),
+		// imageWidth(<bytes>) -> int
+		// imageHeight(<bytes>) -> int
+		// Return the size of a PNG, JPEG, GIF or WebP image in pixels. Other data is an error,
+		// so rules should check isImage first, e.g. isImage(image) && imageWidth(image) <= 1024.
+		cel.Function("imageWidth",
+			cel.Overload("image_width_bytes", []*cel.Type{cel.BytesType}, cel.IntType,
+				cel.UnaryBinding(func(arg ref.Val) ref.Val {
+					config, errVal := imageConfig(arg)
+					if errVal != nil {
+						return errVal
+					}
+					return types.Int(config.Width)
+				}),
+			),
+		),
+		cel.Function("imageHeight",
+			cel.Overload("image_height_bytes", []*cel.Type{cel.BytesType}, cel.IntType,
+				cel.UnaryBinding(func(arg ref.Val) ref.Val {
+					config, errVal := imageConfig(arg)
+					if errVal != nil {
+						return errVal
+					}
+					return types.Int(config.Height)
+				}),
+			),
+		),
+		// hasSignature(<bytes>, <string>) -> bool
+		// Returns whether the data starts with the file signature of the format:
+		// png, jpeg, gif, webp, pdf, zip or gzip.
+		// hasSignature(<bytes>, <bytes>) -> bool
+		// Returns whether the data starts with the given signature.
+		cel.Function(functionHasSignature,
+			cel.Overload("has_signature_bytes_string", []*cel.Type{cel.BytesType, cel.StringType}, cel.BoolType,
+				cel.BinaryBinding(func(data, format ref.Val) ref.Val {
+					matched, known := hasSignature(data.(types.Bytes), string(format.(types.String)))
+					if !known {
+						return types.NewErr("unknown file signature: %s", format)
+					}
+					return types.Bool(matched)
+				}),
+			),
+			cel.Overload("has_signature_bytes_bytes", []*cel.Type{cel.BytesType, cel.BytesType}, cel.BoolType,
+				cel.BinaryBinding(func(data, signature ref.Val) ref.Val {
+					return types.Bool(bytes.HasPrefix(data.(types.Bytes), signature.(types.Bytes)))
+				}),
+			),
+		),
+	}
+}
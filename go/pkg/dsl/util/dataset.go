@@ -0,0 +1,119 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/dsl"
+	"gopkg.in/yaml.v3"
+)
+
+// Datasets looks up the contents of the datasets at evaluation.
+type Datasets interface {
+	Lookup(name string, key string) (string, bool, error)
+}
+
+// StaticDatasets holds the entries of each dataset in memory.
+type StaticDatasets map[string]map[string]string
+
+func (d StaticDatasets) Lookup(name string, key string) (string, bool, error) {
+	entries, ok := d[name]
+	if !ok {
+		return "", false, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("dataset %s is not registered", name))
+	}
+	value, found := entries[key]
+	return value, found, nil
+}
+
+// DatasetEntries validates the dataset and returns its contents as entries.
+// The members of a set are keys with empty values.
+func DatasetEntries(d *dsl.Dataset) (map[string]string, error) {
+	if d.Name == "" {
+		return nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("dataset name is required"))
+	}
+	switch d.Kind {
+	case dsl.DatasetKindSet:
+		if len(d.Entries) != 0 {
+			return nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("set dataset %s cannot have entries", d.Name))
+		}
+		entries := make(map[string]string, len(d.Values))
+		for _, value := range d.Values {
+			entries[value] = ""
+		}
+		return entries, nil
+	case dsl.DatasetKindMap:
+		if len(d.Values) != 0 {
+			return nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("map dataset %s cannot have values", d.Name))
+		}
+		entries := make(map[string]string, len(d.Entries))
+		for key, value := range d.Entries {
+			entries[key] = value
+		}
+		return entries, nil
+	default:
+		return nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("unsupported dataset kind: %s\nplease specify one of the following kinds: set, map", d.Kind))
+	}
+}
+
+// LoadDatasetFile loads the contents of the dataset from its file, relative to baseDir.
+func LoadDatasetFile(d *dsl.Dataset, baseDir string) error {
+	if d.File == "" {
+		return nil
+	}
+	path := d.File
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	switch d.Kind {
+	case dsl.DatasetKindSet:
+		if err := yaml.Unmarshal(content, &d.Values); err != nil {
+			return failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to decode values of dataset %s", d.Name))
+		}
+	case dsl.DatasetKindMap:
+		if err := yaml.Unmarshal(content, &d.Entries); err != nil {
+			return failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to decode entries of dataset %s", d.Name))
+		}
+	default:
+		return failure.New(appError.ErrDSLSyntaxError, failure.Messagef("unsupported dataset kind: %s\nplease specify one of the following kinds: set, map", d.Kind))
+	}
+	return nil
+}
+
+// CheckDatasetReferences checks that the rule refers to the declared datasets by string literals
+// and only looks up values in maps.
+func CheckDatasetReferences(ast *cel.Ast, datasets []dsl.Dataset) error {
+	kinds := make(map[string]string, len(datasets))
+	for _, d := range datasets {
+		kinds[d.Name] = d.Kind
+	}
+	calls := celast.MatchDescendants(celast.NavigateAST(ast.NativeRep()), celast.FunctionMatcher(functionInSet))
+	calls = append(calls, celast.MatchDescendants(celast.NavigateAST(ast.NativeRep()), celast.FunctionMatcher(functionLookup))...)
+	for _, call := range calls {
+		function := call.AsCall().FunctionName()
+		args := call.AsCall().Args()
+		if len(args) == 0 || args[0].Kind() != celast.LiteralKind {
+			return failure.New(appError.ErrDSLSyntaxError, failure.Messagef("dataset name of %s must be a string literal", function))
+		}
+		name, ok := args[0].AsLiteral().(types.String)
+		if !ok {
+			return failure.New(appError.ErrDSLSyntaxError, failure.Messagef("dataset name of %s must be a string literal", function))
+		}
+		kind, ok := kinds[string(name)]
+		if !ok {
+			return failure.New(appError.ErrDSLSyntaxError, failure.Messagef("dataset %s is not declared", name))
+		}
+		if function == functionLookup && kind != dsl.DatasetKindMap {
+			return failure.New(appError.ErrDSLSyntaxError, failure.Messagef("lookup requires a map dataset but %s is a %s", name, kind))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package util
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shopspring/decimal"
+)
+
+// TypeDecimal is the DSL type of arbitrary-precision decimal numbers.
+const TypeDecimal = "decimal"
+
+// DecimalType is the CEL type of Decimal.
+var DecimalType = cel.OpaqueType(TypeDecimal)
+
+// decimalTypeWithTraits is the runtime type of Decimal, which tells the operators that Decimal implements their traits.
+var decimalTypeWithTraits = types.NewObjectType(TypeDecimal,
+	traits.AdderType, traits.SubtractorType, traits.MultiplierType, traits.DividerType, traits.NegatorType, traits.ComparerType)
+
+// Decimal is an arbitrary-precision decimal number as a CEL value.
+type Decimal struct {
+	decimal.Decimal
+}
+
+// ParseDecimal parses a decimal number such as "12.30" or "-1e-3".
+func ParseDecimal(s string) (Decimal, error) {
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		return Decimal{}, failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("invalid decimal: %s", s))
+	}
+	return Decimal{d}, nil
+}
+
+// ToDecimal converts a variable value given as a string or a number to a Decimal.
+// Numbers are converted by their shortest representation, so strings should be used for exact values.
+func ToDecimal(value interface{}) (Decimal, error) {
+	switch v := value.(type) {
+	case Decimal:
+		return v, nil
+	case string:
+		return ParseDecimal(v)
+	case int:
+		return Decimal{decimal.NewFromInt(int64(v))}, nil
+	case int64:
+		return Decimal{decimal.NewFromInt(v)}, nil
+	case float64:
+		return Decimal{decimal.NewFromFloat(v)}, nil
+	default:
+		return Decimal{}, failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("decimal must be given as a string but got %T", value))
+	}
+}
+
+func (d Decimal) ConvertToNative(typeDesc reflect.Type) (any, error) {
+	switch typeDesc {
+	case reflect.TypeOf(d):
+		return d, nil
+	case reflect.TypeOf(d.Decimal):
+		return d.Decimal, nil
+	case reflect.TypeOf(""):
+		return d.String(), nil
+	}
+	return nil, fmt.Errorf("type conversion error from decimal to '%v'", typeDesc)
+}
+
+func (d Decimal) ConvertToType(typeVal ref.Type) ref.Val {
+	switch typeVal {
+	case DecimalType, decimalTypeWithTraits:
+		return d
+	case types.StringType:
+		return types.String(d.String())
+	case types.DoubleType:
+		f, _ := d.Float64()
+		return types.Double(f)
+	case types.TypeType:
+		return decimalTypeWithTraits
+	}
+	return types.NewErr("type conversion error from '%s' to '%s'", DecimalType, typeVal)
+}
+
+func (d Decimal) Equal(other ref.Val) ref.Val {
+	o, ok := other.(Decimal)
+	if !ok {
+		return types.False
+	}
+	return types.Bool(d.Decimal.Equal(o.Decimal))
+}
+
+func (d Decimal) Type() ref.Type {
+	return decimalTypeWithTraits
+}
+
+func (d Decimal) Value() any {
+	return d.Decimal
+}
+
+// Add, Subtract, Multiply, Divide, Negate and Compare implement the CEL traits of the arithmetic and relational operators.
+
+func (d Decimal) Add(other ref.Val) ref.Val {
+	o, ok := other.(Decimal)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(other)
+	}
+	return Decimal{d.Decimal.Add(o.Decimal)}
+}
+
+func (d Decimal) Subtract(other ref.Val) ref.Val {
+	o, ok := other.(Decimal)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(other)
+	}
+	return Decimal{d.Decimal.Sub(o.Decimal)}
+}
+
+func (d Decimal) Multiply(other ref.Val) ref.Val {
+	o, ok := other.(Decimal)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(other)
+	}
+	return Decimal{d.Decimal.Mul(o.Decimal)}
+}
+
+// Divide rounds the quotient to decimal.DivisionPrecision (16) decimal places.
+func (d Decimal) Divide(other ref.Val) ref.Val {
+	o, ok := other.(Decimal)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(other)
+	}
+	if o.IsZero() {
+		return types.NewErr("division by zero")
+	}
+	return Decimal{d.Decimal.Div(o.Decimal)}
+}
+
+func (d Decimal) Negate() ref.Val {
+	return Decimal{d.Decimal.Neg()}
+}
+
+func (d Decimal) Compare(other ref.Val) ref.Val {
+	o, ok := other.(Decimal)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(other)
+	}
+	return types.Int(d.Cmp(o.Decimal))
+}
+
+func decimalFunctions() []cel.EnvOption {
+	decimalUnary := func(op func(x decimal.Decimal) ref.Val) cel.OverloadOpt {
+		return cel.UnaryBinding(func(arg ref.Val) ref.Val {
+			x, ok := arg.(Decimal)
+			if !ok {
+				return types.MaybeNoSuchOverloadErr(arg)
+			}
+			return op(x.Decimal)
+		})
+	}
+	// decimalPlaces binds <decimal>.f(<int>) where the int is a number of decimal places.
+	decimalPlaces := func(op func(x decimal.Decimal, places int32) decimal.Decimal) cel.OverloadOpt {
+		return cel.BinaryBinding(func(lhs, rhs ref.Val) ref.Val {
+			x, ok := lhs.(Decimal)
+			if !ok {
+				return types.MaybeNoSuchOverloadErr(lhs)
+			}
+			places, ok := rhs.(types.Int)
+			if !ok {
+				return types.MaybeNoSuchOverloadErr(rhs)
+			}
+			return Decimal{op(x.Decimal, int32(places))}
+		})
+	}
+	decimalArgs := []*cel.Type{DecimalType, DecimalType}
+
+	return []cel.EnvOption{
+		// decimal(<string>) -> decimal
+		// decimal(<int>) -> decimal
+		cel.Function(TypeDecimal,
+			cel.Overload("string_to_decimal", []*cel.Type{cel.StringType}, DecimalType,
+				cel.UnaryBinding(func(arg ref.Val) ref.Val {
+					d, err := ParseDecimal(string(arg.(types.String)))
+					if err != nil {
+						return types.NewErr("%s", failure.MessageOf(err))
+					}
+					return d
+				}),
+			),
+			cel.Overload("int_to_decimal", []*cel.Type{cel.IntType}, DecimalType,
+				cel.UnaryBinding(func(arg ref.Val) ref.Val {
+					return Decimal{decimal.NewFromInt(int64(arg.(types.Int)))}
+				}),
+			),
+		),
+		// string(<decimal>) -> string
+		cel.Function("string",
+			cel.Overload("decimal_to_string", []*cel.Type{DecimalType}, cel.StringType,
+				decimalUnary(func(x decimal.Decimal) ref.Val { return types.String(x.String()) }),
+			),
+		),
+		// double(<decimal>) -> double
+		cel.Function("double",
+			cel.Overload("decimal_to_double", []*cel.Type{DecimalType}, cel.DoubleType,
+				decimalUnary(func(x decimal.Decimal) ref.Val {
+					f, _ := x.Float64()
+					return types.Double(f)
+				}),
+			),
+		),
+
+		// Operators are dispatched to the traits of Decimal by the standard library,
+		// so their overloads only declare the types.
+		cel.Function(operators.Add, cel.Overload("add_decimal", decimalArgs, DecimalType)),
+		cel.Function(operators.Subtract, cel.Overload("subtract_decimal", decimalArgs, DecimalType)),
+		cel.Function(operators.Multiply, cel.Overload("multiply_decimal", decimalArgs, DecimalType)),
+		cel.Function(operators.Divide, cel.Overload("divide_decimal", decimalArgs, DecimalType)),
+		cel.Function(operators.Negate, cel.Overload("negate_decimal", []*cel.Type{DecimalType}, DecimalType)),
+		cel.Function(operators.Less, cel.Overload("less_decimal", decimalArgs, cel.BoolType)),
+		cel.Function(operators.LessEquals, cel.Overload("less_equals_decimal", decimalArgs, cel.BoolType)),
+		cel.Function(operators.Greater, cel.Overload("greater_decimal", decimalArgs, cel.BoolType)),
+		cel.Function(operators.GreaterEquals, cel.Overload("greater_equals_decimal", decimalArgs, cel.BoolType)),
+
+		// <decimal>.scale() -> int
+		// Returns the number of decimal places, e.g. 2 for decimal("1.50").
+		cel.Function("scale",
+			cel.MemberOverload("decimal_scale", []*cel.Type{DecimalType}, cel.IntType,
+				decimalUnary(func(x decimal.Decimal) ref.Val {
+					if x.Exponent() >= 0 {
+						return types.Int(0)
+					}
+					return types.Int(-x.Exponent())
+				}),
+			),
+		),
+		// <decimal>.abs() -> decimal
+		cel.Function("abs",
+			cel.MemberOverload("decimal_abs", []*cel.Type{DecimalType}, DecimalType,
+				decimalUnary(func(x decimal.Decimal) ref.Val { return Decimal{x.Abs()} }),
+			),
+		),
+		// <decimal>.round(<int>) -> decimal
+		// Rounds half away from zero to the decimal places.
+		cel.Function("round",
+			cel.MemberOverload("decimal_round_int", []*cel.Type{DecimalType, cel.IntType}, DecimalType,
+				decimalPlaces(decimal.Decimal.Round),
+			),
+		),
+		// <decimal>.roundHalfEven(<int>) -> decimal
+		// Rounds half to even (banker's rounding) to the decimal places.
+		cel.Function("roundHalfEven",
+			cel.MemberOverload("decimal_round_half_even_int", []*cel.Type{DecimalType, cel.IntType}, DecimalType,
+				decimalPlaces(decimal.Decimal.RoundBank),
+			),
+		),
+		// <decimal>.truncate(<int>) -> decimal
+		// Drops the digits after the decimal places.
+		cel.Function("truncate",
+			cel.MemberOverload("decimal_truncate_int", []*cel.Type{DecimalType, cel.IntType}, DecimalType,
+				decimalPlaces(decimal.Decimal.Truncate),
+			),
+		),
+	}
+}
@@ -0,0 +1,104 @@
+package util
+
+import (
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/morikuni/failure/v2"
+)
+
+const (
+	functionInSet  = "inSet"
+	functionLookup = "lookup"
+)
+
+// functions returns the functions available to rules in addition to the CEL standard library.
+// datasets may be nil when the rules are only compiled.
+func functions(datasets Datasets) []cel.EnvOption {
+	lookup := func(name, key ref.Val) (string, bool, ref.Val) {
+		if datasets == nil {
+			return "", false, types.NewErr("datasets are not available")
+		}
+		value, found, err := datasets.Lookup(string(name.(types.String)), string(key.(types.String)))
+		if err != nil {
+			return "", false, types.NewErr("%s", failure.MessageOf(err))
+		}
+		return value, found, nil
+	}
+	opts := []cel.EnvOption{
+		// inSet(<dataset>, <string>) -> bool
+		// Returns whether the set (or the keys of the map) contains the value.
+		cel.Function(functionInSet,
+			cel.Overload("in_set_string_string",
+				[]*cel.Type{cel.StringType, cel.StringType},
+				cel.BoolType,
+				cel.BinaryBinding(func(name, key ref.Val) ref.Val {
+					_, found, errVal := lookup(name, key)
+					if errVal != nil {
+						return errVal
+					}
+					return types.Bool(found)
+				}),
+			),
+		),
+		// lookup(<dataset>, <string>) -> string
+		// lookup(<dataset>, <string>, <default>) -> string
+		// Returns the value of the key in the map. Without a default, a missing key is an error.
+		cel.Function(functionLookup,
+			cel.Overload("lookup_string_string",
+				[]*cel.Type{cel.StringType, cel.StringType},
+				cel.StringType,
+				cel.BinaryBinding(func(name, key ref.Val) ref.Val {
+					value, found, errVal := lookup(name, key)
+					if errVal != nil {
+						return errVal
+					}
+					if !found {
+						return types.NewErr("no such key in dataset %s: %s", name, key)
+					}
+					return types.String(value)
+				}),
+			),
+			cel.Overload("lookup_string_string_string",
+				[]*cel.Type{cel.StringType, cel.StringType, cel.StringType},
+				cel.StringType,
+				cel.FunctionBinding(func(args ...ref.Val) ref.Val {
+					value, found, errVal := lookup(args[0], args[1])
+					if errVal != nil {
+						return errVal
+					}
+					if !found {
+						return args[2]
+					}
+					return types.String(value)
+				}),
+			),
+		),
+		// <list>.indices() -> list(int)
+		// Returns the indexes of the list so that rules can report offending elements by index.
+		// e.g. items.indices().filter(i, items[i] <= 0).map(i, {"index": i, "message": "must be positive"})
+		cel.Function("indices",
+			cel.MemberOverload("list_indices",
+				[]*cel.Type{cel.ListType(cel.TypeParamType("T"))},
+				cel.ListType(cel.IntType),
+				cel.UnaryBinding(func(arg ref.Val) ref.Val {
+					list, ok := arg.(traits.Lister)
+					if !ok {
+						return types.MaybeNoSuchOverloadErr(arg)
+					}
+					size := list.Size().(types.Int)
+					indices := make([]int64, size)
+					for i := range indices {
+						indices[i] = int64(i)
+					}
+					return types.DefaultTypeAdapter.NativeToValue(indices)
+				}),
+			),
+		),
+	}
+	opts = append(opts, decimalFunctions()...)
+	opts = append(opts, binaryFunctions()...)
+	opts = append(opts, textFunctions()...)
+	return opts
+}
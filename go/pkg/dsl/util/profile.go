@@ -0,0 +1,64 @@
+package util
+
+import (
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/dsl"
+)
+
+// FindProfile returns the profile of the validation, or nil if the name is empty.
+func FindProfile(validation *dsl.Validation, name string) (*dsl.Profile, error) {
+	if name == "" {
+		return nil, nil
+	}
+	for i := range validation.Profiles {
+		if validation.Profiles[i].Name == name {
+			return &validation.Profiles[i], nil
+		}
+	}
+	return nil, failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("profile %s is not defined in validation %s", name, validation.ID))
+}
+
+// CheckProfiles checks that the profiles have unique names and refer to the rules and variables of the validation.
+// It returns the indexes of the excluded rules per profile.
+func CheckProfiles(validation *dsl.Validation) ([][]int, error) {
+	excludes := make([][]int, len(validation.Profiles))
+	names := make(map[string]bool, len(validation.Profiles))
+	for i, profile := range validation.Profiles {
+		if profile.Name == "" {
+			return nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("profile name is required in validation %s", validation.ID))
+		}
+		if names[profile.Name] {
+			return nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("profile %s is already defined in validation %s", profile.Name, validation.ID))
+		}
+		names[profile.Name] = true
+
+		excludes[i] = make([]int, 0, len(profile.Exclude))
+		for _, excluded := range profile.Exclude {
+			index := -1
+			for j, cel := range validation.Cels {
+				if cel == excluded {
+					index = j
+					break
+				}
+			}
+			if index < 0 {
+				return nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("profile %s excludes an undefined rule: %s", profile.Name, excluded))
+			}
+			excludes[i] = append(excludes[i], index)
+		}
+		for _, optional := range profile.Optional {
+			declared := false
+			for _, variable := range validation.Variables {
+				if variable.Name == optional {
+					declared = true
+					break
+				}
+			}
+			if !declared {
+				return nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("profile %s marks an undeclared variable optional: %s", profile.Name, optional))
+			}
+		}
+	}
+	return excludes, nil
+}
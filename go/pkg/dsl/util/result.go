@@ -0,0 +1,109 @@
+package util
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+)
+
+// ResultError is an error reported by a rule returning a string or a list.
+type ResultError struct {
+	Message string
+	// Attributes are the entries of an error object other than message (e.g. index).
+	Attributes map[string]string
+}
+
+// CheckResultType checks that the rule returns one of the supported result types:
+//   - bool: true means pass
+//   - string: an error message, empty means pass
+//   - list(string) or list(map(string, dyn)): the errors, empty means pass.
+//     An error object has a message entry and any other entries as its attributes.
+func CheckResultType(ast *cel.Ast) error {
+	outputType := ast.OutputType()
+	switch outputType.Kind() {
+	case types.BoolKind, types.StringKind, types.DynKind:
+		return nil
+	case types.ListKind:
+		elemType := outputType.Parameters()[0]
+		switch elemType.Kind() {
+		case types.StringKind, types.DynKind:
+			return nil
+		case types.MapKind:
+			keyType := elemType.Parameters()[0]
+			if keyType.Kind() == types.StringKind || keyType.Kind() == types.DynKind {
+				return nil
+			}
+		}
+	}
+	return failure.New(appError.ErrDSLSyntaxError, failure.Messagef("unsupported result type: %s\nplease return one of the following types: bool, string, list(string), list(map(string, dyn))", outputType))
+}
+
+// ParseResult returns whether the rule passed and the errors it reported.
+// A rule returning false reports no errors.
+func ParseResult(val ref.Val) (bool, []ResultError, error) {
+	switch val := val.(type) {
+	case types.Bool:
+		return bool(val), nil, nil
+	case types.String:
+		if val == "" {
+			return true, nil, nil
+		}
+		return false, []ResultError{{Message: string(val)}}, nil
+	case traits.Lister:
+		resultErrors := make([]ResultError, 0)
+		it := val.Iterator()
+		for it.HasNext() == types.True {
+			resultError, err := parseResultError(it.Next())
+			if err != nil {
+				return false, nil, err
+			}
+			if resultError != nil {
+				resultErrors = append(resultErrors, *resultError)
+			}
+		}
+		return len(resultErrors) == 0, resultErrors, nil
+	default:
+		return false, nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("unsupported result type: %s", val.Type().TypeName()))
+	}
+}
+
+func parseResultError(val ref.Val) (*ResultError, error) {
+	switch val := val.(type) {
+	case types.String:
+		if val == "" {
+			return nil, nil
+		}
+		return &ResultError{Message: string(val)}, nil
+	case traits.Mapper:
+		resultError := &ResultError{Attributes: make(map[string]string)}
+		it := val.Iterator()
+		for it.HasNext() == types.True {
+			key := it.Next()
+			name, ok := key.(types.String)
+			if !ok {
+				return nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("unsupported error object key type: %s", key.Type().TypeName()))
+			}
+			value := val.Get(key)
+			if name == "message" {
+				message, ok := value.(types.String)
+				if !ok {
+					return nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("message of error object must be string but got %s", value.Type().TypeName()))
+				}
+				resultError.Message = string(message)
+				continue
+			}
+			resultError.Attributes[string(name)] = fmt.Sprintf("%v", value.Value())
+		}
+		if resultError.Message == "" {
+			return nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("error object must have a message"))
+		}
+		return resultError, nil
+	default:
+		return nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("unsupported error type: %s", val.Type().TypeName()))
+	}
+}
@@ -0,0 +1,133 @@
+package util
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/rivo/uniseg"
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+)
+
+const (
+	// prolongedSoundMark (ー) is used in both hiragana and katakana words.
+	prolongedSoundMark = 'ー'
+	// halfWidthProlongedSoundMark (ｰ) is used in half-width katakana words.
+	halfWidthProlongedSoundMark = 'ｰ'
+)
+
+var normalizationForms = map[string]norm.Form{
+	"NFC":  norm.NFC,
+	"NFD":  norm.NFD,
+	"NFKC": norm.NFKC,
+	"NFKD": norm.NFKD,
+}
+
+// isWide reports whether the rune takes two columns in East Asian contexts.
+func isWide(r rune) bool {
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return true
+	default:
+		return false
+	}
+}
+
+// allRunes reports whether the string is not empty and every rune satisfies f.
+func allRunes(s string, f func(r rune) bool) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !f(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isHiragana(r rune) bool {
+	return unicode.Is(unicode.Hiragana, r) || r == prolongedSoundMark
+}
+
+func isKatakana(r rune) bool {
+	return unicode.Is(unicode.Katakana, r) || r == prolongedSoundMark || r == halfWidthProlongedSoundMark
+}
+
+func isKanji(r rune) bool {
+	return unicode.Is(unicode.Han, r)
+}
+
+func textFunctions() []cel.EnvOption {
+	stringMember := func(name string, resultType *cel.Type, f func(s string) ref.Val) cel.EnvOption {
+		return cel.Function(name,
+			cel.MemberOverload("string_"+name, []*cel.Type{cel.StringType}, resultType,
+				cel.UnaryBinding(func(arg ref.Val) ref.Val {
+					return f(string(arg.(types.String)))
+				}),
+			),
+		)
+	}
+	return []cel.EnvOption{
+		// <string>.graphemeLength() -> int
+		// Returns the number of user-perceived characters, e.g. 1 for "👨‍👩‍👧" or "が" written with a combining mark.
+		stringMember("graphemeLength", cel.IntType, func(s string) ref.Val {
+			return types.Int(uniseg.GraphemeClusterCount(s))
+		}),
+		// <string>.displayWidth() -> int
+		// Returns the width in columns where East Asian wide and full-width characters take two.
+		stringMember("displayWidth", cel.IntType, func(s string) ref.Val {
+			return types.Int(uniseg.StringWidth(s))
+		}),
+		// <string>.isFullWidth() -> bool
+		// Returns whether the string is not empty and consists of wide or full-width characters.
+		stringMember("isFullWidth", cel.BoolType, func(s string) ref.Val {
+			return types.Bool(allRunes(s, isWide))
+		}),
+		// <string>.isHalfWidth() -> bool
+		// Returns whether the string is not empty and has no wide or full-width characters.
+		stringMember("isHalfWidth", cel.BoolType, func(s string) ref.Val {
+			return types.Bool(allRunes(s, func(r rune) bool { return !isWide(r) }))
+		}),
+		// <string>.toFullWidth() -> string
+		// Converts half-width characters such as "ｱ" and "A" to their full-width forms.
+		stringMember("toFullWidth", cel.StringType, func(s string) ref.Val {
+			return types.String(width.Widen.String(s))
+		}),
+		// <string>.toHalfWidth() -> string
+		// Converts full-width characters such as "Ａ" and "ア" to their half-width forms.
+		stringMember("toHalfWidth", cel.StringType, func(s string) ref.Val {
+			return types.String(width.Narrow.String(s))
+		}),
+		// <string>.isHiragana() -> bool
+		// <string>.isKatakana() -> bool
+		// <string>.isKanji() -> bool
+		// Return whether the string is not empty and consists of the character class.
+		// The prolonged sound mark "ー" counts as both hiragana and katakana.
+		stringMember("isHiragana", cel.BoolType, func(s string) ref.Val {
+			return types.Bool(allRunes(s, isHiragana))
+		}),
+		stringMember("isKatakana", cel.BoolType, func(s string) ref.Val {
+			return types.Bool(allRunes(s, isKatakana))
+		}),
+		stringMember("isKanji", cel.BoolType, func(s string) ref.Val {
+			return types.Bool(allRunes(s, isKanji))
+		}),
+		// <string>.normalize(<string>) -> string
+		// Returns the string in the Unicode normalization form: NFC, NFD, NFKC or NFKD.
+		cel.Function("normalize",
+			cel.MemberOverload("string_normalize_string", []*cel.Type{cel.StringType, cel.StringType}, cel.StringType,
+				cel.BinaryBinding(func(arg, form ref.Val) ref.Val {
+					f, ok := normalizationForms[strings.ToUpper(string(form.(types.String)))]
+					if !ok {
+						return types.NewErr("unknown normalization form: %s", form)
+					}
+					return types.String(f.String(string(arg.(types.String))))
+				}),
+			),
+		),
+	}
+}
@@ -3,15 +3,30 @@ package util
 import (
 	"io"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/google/cel-go/cel"
 	"github.com/morikuni/failure/v2"
 	"github.com/shibukazu/open-ve/go/pkg/appError"
 	"github.com/shibukazu/open-ve/go/pkg/dsl"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"gopkg.in/yaml.v3"
 )
 
-func DSLVariableToCELVariable(v *dsl.Variable) (cel.EnvOption, error) {
+// DSLVariableToCELVariable converts a DSL variable to a CEL variable declaration.
+// Types other than the primitive types are resolved as message types of files, which may be nil.
+func DSLVariableToCELVariable(v *dsl.Variable, files *protoregistry.Files) (cel.EnvOption, error) {
+	if IsReservedVariableName(v.Name) {
+		return nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("variable name %s is reserved", v.Name))
+	}
 	switch v.Type {
 	case "int":
 		return cel.Variable(v.Name, cel.IntType), nil
@@ -25,16 +40,31 @@ func DSLVariableToCELVariable(v *dsl.Variable) (cel.EnvOption, error) {
 		return cel.Variable(v.Name, cel.BytesType), nil
 	case "string":
 		return cel.Variable(v.Name, cel.StringType), nil
+	case TypeDecimal:
+		return cel.Variable(v.Name, DecimalType), nil
 	// TODO: listとmap向けの再帰パースの実装
 	default:
-		return nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("unsupported variable type: %s\nplease specify one of the following types: int, uint, double, bool, string, bytes", v.Type))
+		if _, err := FindMessageDescriptor(files, v.Type); err == nil {
+			return cel.Variable(v.Name, cel.ObjectType(v.Type)), nil
+		}
+		return nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("unsupported variable type: %s\nplease specify one of the following types: int, uint, double, bool, string, bytes, decimal, or a message type of the registered file descriptor set", v.Type))
 	}
 }
 
-func DSLVariablesToCELVariables(vars []dsl.Variable) ([]cel.EnvOption, error) {
+// IsPrimitiveType reports whether the DSL type is one of the primitive types rather than a message type.
+func IsPrimitiveType(t string) bool {
+	switch t {
+	case "int", "uint", "double", "bool", "bytes", "string", TypeDecimal:
+		return true
+	default:
+		return false
+	}
+}
+
+func DSLVariablesToCELVariables(vars []dsl.Variable, files *protoregistry.Files) ([]cel.EnvOption, error) {
 	celVars := make([]cel.EnvOption, 0, len(vars))
 	for _, v := range vars {
-		v, err := DSLVariableToCELVariable(&v)
+		v, err := DSLVariableToCELVariable(&v, files)
 		if err != nil {
 			return nil, err
 		}
@@ -43,6 +73,157 @@ func DSLVariablesToCELVariables(vars []dsl.Variable) ([]cel.EnvOption, error) {
 	return celVars, nil
 }
 
+const (
+	// ContextVariableNamespace qualifies the context variables so that they do not shadow the declared ones.
+	ContextVariableNamespace = "openve"
+	// ContextVariableNow is the time of the request.
+	ContextVariableNow = ContextVariableNamespace + ".now"
+	// ContextVariableRequest holds principal, metadata and time of the request.
+	ContextVariableRequest = ContextVariableNamespace + ".request"
+)
+
+// IsReservedVariableName reports whether the name is reserved for the context variables.
+func IsReservedVariableName(name string) bool {
+	return name == ContextVariableNamespace || strings.HasPrefix(name, ContextVariableNamespace+".")
+}
+
+// ContextVariables returns the values of the context variables injected into every evaluation.
+func ContextVariables(principal string, metadata map[string]string, now time.Time) map[string]interface{} {
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	timestamp := timestamppb.New(now)
+	return map[string]interface{}{
+		ContextVariableNow: timestamp,
+		ContextVariableRequest: map[string]interface{}{
+			"principal": principal,
+			"metadata":  metadata,
+			"time":      timestamp,
+		},
+	}
+}
+
+// NewCELEnv creates the CEL environment in which the rules of a validation are compiled and evaluated.
+// datasets may be nil when the rules are only compiled.
+func NewCELEnv(vars []dsl.Variable, files *protoregistry.Files, datasets Datasets) (*cel.Env, error) {
+	celVariables, err := DSLVariablesToCELVariables(vars, files)
+	if err != nil {
+		return nil, err
+	}
+	// Macro calls are tracked so that compiled ASTs can be converted back to string.
+	opts := append(celVariables, cel.EnableMacroCallTracking())
+	opts = append(opts,
+		cel.Variable(ContextVariableNow, cel.TimestampType),
+		cel.Variable(ContextVariableRequest, cel.MapType(cel.StringType, cel.DynType)),
+	)
+	opts = append(opts, functions(datasets)...)
+	if files != nil {
+		opts = append(opts, cel.TypeDescs(files))
+	}
+	env, err := cel.NewEnv(opts...)
+	if err != nil {
+		return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to create CEL environment: %v", err))
+	}
+	return env, nil
+}
+
+// CompileComputedVariables compiles the computed variables in order.
+// Each one can refer to the variables and the preceding computed variables.
+// It returns the environment extended with the computed variables, in which the rules are compiled.
+func CompileComputedVariables(env *cel.Env, vars []dsl.Variable, computed []dsl.ComputedVariable) (*cel.Env, []*cel.Ast, error) {
+	declared := make(map[string]bool, len(vars)+len(computed))
+	for _, v := range vars {
+		declared[v.Name] = true
+	}
+	asts := make([]*cel.Ast, 0, len(computed))
+	for _, c := range computed {
+		if c.Name == "" {
+			return nil, nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("computed variable name is required"))
+		}
+		if IsReservedVariableName(c.Name) {
+			return nil, nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("variable name %s is reserved", c.Name))
+		}
+		if declared[c.Name] {
+			return nil, nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("computed variable %s is already declared", c.Name))
+		}
+		declared[c.Name] = true
+
+		ast, issues := env.Compile(c.Cel)
+		if issues != nil && issues.Err() != nil {
+			return nil, nil, failure.Translate(issues.Err(), appError.ErrDSLSyntaxError, failure.Messagef("failed to compile computed variable %s", c.Name))
+		}
+		var err error
+		env, err = ExtendComputedVariable(env, c.Name, ast)
+		if err != nil {
+			return nil, nil, err
+		}
+		asts = append(asts, ast)
+	}
+	return env, asts, nil
+}
+
+// ExtendComputedVariable declares the computed variable with the result type of its AST.
+func ExtendComputedVariable(env *cel.Env, name string, ast *cel.Ast) (*cel.Env, error) {
+	extended, err := env.Extend(cel.Variable(name, ast.OutputType()))
+	if err != nil {
+		return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to declare computed variable %s: %v", name, err))
+	}
+	return extended, nil
+}
+
+// ReferencedVariables returns the names of the variables the expression depends on.
+// dependencies maps each declared name to the variables it depends on.
+func ReferencedVariables(ast *cel.Ast, dependencies map[string][]string) []string {
+	nativeAST := ast.NativeRep()
+	seen := make(map[string]bool)
+	names := make([]string, 0)
+	for _, reference := range nativeAST.ReferenceMap() {
+		if reference.Value != nil || reference.Name == "" || len(reference.OverloadIDs) != 0 {
+			continue
+		}
+		for _, name := range dependencies[reference.Name] {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseFileDescriptorSet parses a serialized FileDescriptorSet, which must include all imports.
+// It returns nil if the set is empty.
+func ParseFileDescriptorSet(fileDescriptorSet []byte) (*protoregistry.Files, error) {
+	if len(fileDescriptorSet) == 0 {
+		return nil, nil
+	}
+	fds := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(fileDescriptorSet, fds); err != nil {
+		return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to decode file descriptor set"))
+	}
+	files, err := protodesc.NewFiles(fds)
+	if err != nil {
+		return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to resolve file descriptor set (build it with imports included)"))
+	}
+	return files, nil
+}
+
+func FindMessageDescriptor(files *protoregistry.Files, name string) (protoreflect.MessageDescriptor, error) {
+	if files == nil {
+		return nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("message type %s is not registered", name))
+	}
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("message type %s is not registered", name))
+	}
+	md, ok := desc.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("%s is not a message type", name))
+	}
+	return md, nil
+}
+
 func ParseDSLYAML(yamlFilePath string) (*dsl.DSL, error) {
 	yamlFile, err := os.Open(yamlFilePath)
 	if err != nil {
@@ -61,5 +242,22 @@ func ParseDSLYAML(yamlFilePath string) (*dsl.DSL, error) {
 		return nil, err
 	}
 
+	for i := range dsl.Datasets {
+		if err := LoadDatasetFile(&dsl.Datasets[i], filepath.Dir(yamlFilePath)); err != nil {
+			return nil, err
+		}
+	}
+
+	if dsl.FileDescriptorSetPath != "" {
+		fileDescriptorSetPath := dsl.FileDescriptorSetPath
+		if !filepath.IsAbs(fileDescriptorSetPath) {
+			fileDescriptorSetPath = filepath.Join(filepath.Dir(yamlFilePath), fileDescriptorSetPath)
+		}
+		dsl.FileDescriptorSet, err = os.ReadFile(fileDescriptorSetPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return dsl, nil
 }
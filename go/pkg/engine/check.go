@@ -0,0 +1,191 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/shibukazu/open-ve/go/pkg/validator"
+)
+
+// Result is the result of checking variables against a validation.
+type Result struct {
+	IsValid bool
+	Message string
+	// Errors has an entry per failed boolean rule and per error reported by the other rules.
+	Errors       []RuleError
+	Explanations []Explanation
+	// Computed is the value of each computed variable. It is only set with WithExplain.
+	Computed map[string]string
+}
+
+// RuleError is a failed rule, or an error reported by a rule returning errors.
+type RuleError struct {
+	Cel        string
+	Message    string
+	Attributes map[string]string
+}
+
+// Explanation is the evaluation of a rule with the value of each of its sub-expressions.
+type Explanation struct {
+	Cel         string
+	IsValid     bool
+	Variables   map[string]string
+	Expressions []EvaluatedExpression
+}
+
+type EvaluatedExpression struct {
+	Expression string
+	Value      string
+}
+
+// RecordResult is the result of a record of CheckBulk. Err is set when the record could not be evaluated.
+type RecordResult struct {
+	Index   int
+	IsValid bool
+	Message string
+	Errors  []RuleError
+	Err     error
+}
+
+// DocumentFailure is a failed rule or a variable that could not be extracted from the document.
+type DocumentFailure struct {
+	Cel     string
+	Paths   []string
+	Message string
+}
+
+// DocumentResult is the result of checking a document.
+type DocumentResult struct {
+	IsValid  bool
+	Message  string
+	Failures []DocumentFailure
+}
+
+// RequestContext is exposed to the rules as the openve.now and openve.request variables.
+type RequestContext struct {
+	Principal string
+	// Metadata are the selected gRPC metadata (or HTTP headers) of the request.
+	Metadata map[string]string
+	Time     time.Time
+}
+
+// WithRequestContext attaches the request context to ctx for the checks.
+// Without it, the rules see the time of the check and an empty request.
+func WithRequestContext(ctx context.Context, requestContext *RequestContext) context.Context {
+	return validator.WithRequestContext(ctx, &validator.RequestContext{
+		Principal: requestContext.Principal,
+		Metadata:  requestContext.Metadata,
+		Time:      requestContext.Time,
+	})
+}
+
+// CheckOption configures a check.
+type CheckOption func(*checkOptions)
+
+type checkOptions struct {
+	profile string
+	explain bool
+}
+
+// WithProfile checks with the rules adjusted by the profile of the validation.
+func WithProfile(profile string) CheckOption {
+	return func(o *checkOptions) {
+		o.profile = profile
+	}
+}
+
+// WithExplain evaluates every rule exhaustively and returns the value of each sub-expression.
+func WithExplain() CheckOption {
+	return func(o *checkOptions) {
+		o.explain = true
+	}
+}
+
+// Check checks the variables against the validation.
+// Variables are Go values of the declared types, e.g. int64 or int for int, []byte for bytes,
+// a string for decimal and a proto.Message for message types.
+func (e *Engine) Check(ctx context.Context, id string, variables map[string]interface{}, opts ...CheckOption) (*Result, error) {
+	o := &checkOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	var result *validator.Result
+	var err error
+	if o.explain {
+		result, err = e.validator.Explain(ctx, id, o.profile, variables)
+	} else {
+		result, err = e.validator.Validate(ctx, id, o.profile, variables)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		IsValid:      result.IsValid,
+		Message:      result.Message,
+		Errors:       toRuleErrors(result.Errors),
+		Explanations: toExplanations(result.Explanations),
+		Computed:     result.Computed,
+	}, nil
+}
+
+// CheckDocument extracts the variables from a decoded JSON document by their declared paths and checks them.
+func (e *Engine) CheckDocument(ctx context.Context, id string, document interface{}) (*DocumentResult, error) {
+	isValid, message, failures, err := e.validator.ValidateDocument(ctx, id, document)
+	if err != nil {
+		return nil, err
+	}
+	result := &DocumentResult{IsValid: isValid, Message: message, Failures: make([]DocumentFailure, len(failures))}
+	for i, failure := range failures {
+		result.Failures[i] = DocumentFailure{Cel: failure.Cel, Paths: failure.Paths, Message: failure.Message}
+	}
+	return result, nil
+}
+
+// CheckBulk checks many records against the validation. A record that fails to evaluate does not abort the others.
+func (e *Engine) CheckBulk(ctx context.Context, id string, records []map[string]interface{}) ([]RecordResult, error) {
+	results, err := e.validator.ValidateBulk(ctx, id, records)
+	if err != nil {
+		return nil, err
+	}
+	recordResults := make([]RecordResult, len(results))
+	for i, result := range results {
+		recordResults[i] = RecordResult{
+			Index:   result.Index,
+			IsValid: result.IsValid,
+			Message: result.Message,
+			Errors:  toRuleErrors(result.Errors),
+			Err:     result.Err,
+		}
+	}
+	return recordResults, nil
+}
+
+func toRuleErrors(ruleErrors []validator.RuleError) []RuleError {
+	if ruleErrors == nil {
+		return nil
+	}
+	converted := make([]RuleError, len(ruleErrors))
+	for i, ruleError := range ruleErrors {
+		converted[i] = RuleError{Cel: ruleError.Cel, Message: ruleError.Message, Attributes: ruleError.Attributes}
+	}
+	return converted
+}
+
+func toExplanations(explanations []validator.Explanation) []Explanation {
+	if explanations == nil {
+		return nil
+	}
+	converted := make([]Explanation, len(explanations))
+	for i, explanation := range explanations {
+		converted[i] = Explanation{
+			Cel:         explanation.Cel,
+			IsValid:     explanation.IsValid,
+			Variables:   explanation.Variables,
+			Expressions: make([]EvaluatedExpression, len(explanation.Expressions)),
+		}
+		for j, expression := range explanation.Expressions {
+			converted[i].Expressions[j] = EvaluatedExpression{Expression: expression.Expression, Value: expression.Value}
+		}
+	}
+	return converted
+}
@@ -0,0 +1,156 @@
+// Package engine evaluates Open-VE validations in process.
+// It does not depend on the gRPC and HTTP servers, which use it to evaluate the requests.
+//
+//	e := engine.New()
+//	if err := e.LoadFile(ctx, "dsl.yml"); err != nil {
+//		return err
+//	}
+//	result, err := e.Check(ctx, "item", map[string]interface{}{"price": 100}, engine.WithProfile("create"))
+package engine
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	dslPkg "github.com/shibukazu/open-ve/go/pkg/dsl"
+	"github.com/shibukazu/open-ve/go/pkg/dsl/bundle"
+	"github.com/shibukazu/open-ve/go/pkg/dsl/reader"
+	"github.com/shibukazu/open-ve/go/pkg/dsl/util"
+	"github.com/shibukazu/open-ve/go/pkg/store"
+	"github.com/shibukazu/open-ve/go/pkg/validator"
+	pbBundle "github.com/shibukazu/open-ve/go/proto/bundle/v1"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+type Engine struct {
+	logger    *slog.Logger
+	store     store.Store
+	dslReader *reader.DSLReader
+	validator *validator.Validator
+}
+
+// Option configures an Engine.
+type Option func(*Engine)
+
+// WithLogger sets the logger. Nothing is logged by default.
+func WithLogger(logger *slog.Logger) Option {
+	return func(e *Engine) {
+		e.logger = logger
+	}
+}
+
+// WithStore sets the store the DSL is kept in, e.g. a Redis store shared by several processes.
+// An in-memory store is used by default.
+func WithStore(store store.Store) Option {
+	return func(e *Engine) {
+		e.store = store
+	}
+}
+
+func New(opts ...Option) *Engine {
+	e := &Engine{}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.logger == nil {
+		e.logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	if e.store == nil {
+		e.store = store.NewMemoryStore("engine")
+	}
+	e.dslReader = reader.NewDSLReader(e.logger, e.store)
+	e.validator = validator.NewValidator(e.logger, e.store, e.dslReader)
+	return e
+}
+
+// Load compiles the DSL and replaces the loaded one with it.
+func (e *Engine) Load(ctx context.Context, dsl *dslPkg.DSL) error {
+	return e.dslReader.Register(ctx, dsl)
+}
+
+// LoadFile loads a DSL YAML file with the datasets and the file descriptor set it refers to.
+func (e *Engine) LoadFile(ctx context.Context, path string) error {
+	dsl, err := util.ParseDSLYAML(path)
+	if err != nil {
+		return err
+	}
+	return e.Load(ctx, dsl)
+}
+
+// LoadBundle loads a serialized bundle of compiled rules without compiling them again.
+// The signature of the bundle, if any, should be verified with bundle.Verify beforehand.
+func (e *Engine) LoadBundle(ctx context.Context, data []byte) error {
+	dsl, compiled, err := bundle.Decode(data)
+	if err != nil {
+		return err
+	}
+	return e.dslReader.RegisterCompiled(ctx, dsl, compiled)
+}
+
+// LoadDataset replaces the contents of a dataset declared by the loaded DSL.
+func (e *Engine) LoadDataset(ctx context.Context, dataset *dslPkg.Dataset) error {
+	return e.dslReader.RegisterDataset(ctx, dataset)
+}
+
+// DSL returns the loaded DSL with the contents of its datasets.
+func (e *Engine) DSL(ctx context.Context) (*dslPkg.DSL, error) {
+	return e.dslReader.ReadWithDatasets(ctx)
+}
+
+// Schema returns the loaded DSL with its datasets declared but without their contents.
+func (e *Engine) Schema(ctx context.Context) (*dslPkg.DSL, error) {
+	return e.dslReader.Read(ctx)
+}
+
+// Dataset returns a dataset of the loaded DSL with its contents.
+func (e *Engine) Dataset(ctx context.Context, name string) (*dslPkg.Dataset, error) {
+	return e.dslReader.ReadDataset(ctx, name)
+}
+
+// HasValidation reports whether the loaded DSL has the validation ID.
+// Loads by other engines sharing the store are only seen while Watch runs.
+func (e *Engine) HasValidation(ctx context.Context, id string) (bool, error) {
+	return e.dslReader.HasValidation(ctx, id)
+}
+
+// VariableTypes returns the declared type of each variable of the validation.
+func (e *Engine) VariableTypes(ctx context.Context, id string) (map[string]string, error) {
+	return e.dslReader.GetVariableNameToCELType(ctx, id)
+}
+
+// Files returns the message types of the loaded file descriptor set, or nil if the DSL has none.
+func (e *Engine) Files(ctx context.Context) (*protoregistry.Files, error) {
+	return e.dslReader.ReadFileDescriptorSet(ctx)
+}
+
+// Bundle returns the loaded DSL with its compiled rules, which LoadBundle loads after serialization.
+func (e *Engine) Bundle(ctx context.Context) (*pbBundle.Bundle, error) {
+	dsl, err := e.dslReader.ReadWithDatasets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	compiled := make(map[string]*store.CompiledValidation, len(dsl.Validations))
+	for _, validation := range dsl.Validations {
+		compiled[validation.ID], err = e.dslReader.ReadCompiledValidation(ctx, validation.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return bundle.Build(dsl, compiled)
+}
+
+// Changed returns a channel closed when a DSL or a dataset is loaded next.
+// Loads by other engines sharing the store are only seen while Watch runs.
+// It should be obtained before reading the DSL so that no load in between is missed.
+func (e *Engine) Changed() <-chan struct{} {
+	return e.dslReader.Changed()
+}
+
+// Watch follows the loads by other engines sharing the store until ctx is canceled.
+// The version of the store is also checked at the interval unless it is zero.
+func (e *Engine) Watch(ctx context.Context, wg *sync.WaitGroup, interval time.Duration) {
+	e.dslReader.WatchStore(ctx, wg, interval)
+}
@@ -0,0 +1,73 @@
+package namespace
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+
+	grpcauth "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/authn"
+)
+
+// Authenticator accepts the keys of the namespace selected by a request in addition to those of the node.
+// A namespace with keys does not accept anonymous clients, even if the node does without authentication.
+// Requests not selecting a namespace, including those managing the namespaces, are authenticated by the node only.
+type Authenticator struct {
+	node       authn.Authenticator
+	namespaces *Manager
+}
+
+func NewAuthenticator(node authn.Authenticator, namespaces *Manager) *Authenticator {
+	return &Authenticator{node: node, namespaces: namespaces}
+}
+
+func (a *Authenticator) Authenticate(ctx context.Context) (string, error) {
+	name := FromContext(ctx)
+	if name == "" {
+		return a.node.Authenticate(ctx)
+	}
+	namespace, err := a.namespaces.Read(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if key, err := grpcauth.AuthFromMD(ctx, "Bearer"); err == nil {
+		if matchKey(namespace.PrivilegedKeyHash, key) {
+			return authn.PrincipalPresharedPrivileged, nil
+		}
+		if matchKey(namespace.PresharedKeyHash, key) {
+			return authn.PrincipalPreshared, nil
+		}
+	}
+	principal, err := a.node.Authenticate(ctx)
+	if err != nil {
+		return "", err
+	}
+	if principal == "" && (len(namespace.PresharedKeyHash) != 0 || len(namespace.PrivilegedKeyHash) != 0) {
+		return "", failure.New(appError.ErrAuthenticationFailed, failure.Messagef("namespace %s requires its key", name))
+	}
+	return principal, nil
+}
+
+// Authorize grants the permissions to the privileged key of the namespace as well as to that of the node.
+func (a *Authenticator) Authorize(ctx context.Context, permission authn.Permission) error {
+	if name := FromContext(ctx); name != "" {
+		namespace, err := a.namespaces.Read(ctx, name)
+		if err != nil {
+			return err
+		}
+		if key, err := grpcauth.AuthFromMD(ctx, "Bearer"); err == nil && matchKey(namespace.PrivilegedKeyHash, key) {
+			return nil
+		}
+	}
+	return a.node.Authorize(ctx, permission)
+}
+
+func matchKey(hash []byte, key string) bool {
+	if len(hash) == 0 {
+		return false
+	}
+	keyHash := sha256.Sum256([]byte(key))
+	return subtle.ConstantTimeCompare(hash, keyHash[:]) == 1
+}
@@ -0,0 +1,231 @@
+// Package namespace serves the namespaces of a node: tenants with a schema, datasets, a version and keys of their own.
+// The default namespace, selected when a request names none, is the schema of the node itself.
+package namespace
+
+import (
+	"context"
+	"crypto/sha256"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/engine"
+	storePkg "github.com/shibukazu/open-ve/go/pkg/store"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// Header selects the namespace of an HTTP request. The gateway forwards it to gRPC as MetadataKey.
+	Header = "X-Open-VE-Namespace"
+	// MetadataKey selects the namespace of a gRPC request.
+	MetadataKey = "x-open-ve-namespace"
+	// PathPrefix selects the namespace of an HTTP request by the path, as in /namespaces/{name}/v1/check.
+	PathPrefix = "/namespaces/"
+)
+
+// namePattern allows names usable as DNS labels, which do not contain the separators of the store keys.
+var namePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("invalid namespace name %q: lowercase letters, digits and hyphens up to 63 characters are allowed", name))
+	}
+	return nil
+}
+
+// FromContext returns the namespace selected by the metadata of a gRPC request, or an empty string for the default namespace.
+func FromContext(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(MetadataKey); len(values) != 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// FromRequest returns the namespace selected by the header of an HTTP request, or an empty string for the default namespace.
+func FromRequest(r *http.Request) string {
+	return r.Header.Get(Header)
+}
+
+type namespaceEngine struct {
+	engine *engine.Engine
+	// cancel stops the store watcher of the engine. It is nil while the watchers are not running.
+	cancel context.CancelFunc
+}
+
+// Manager holds the engines of the namespaces of a node.
+// The engine of a namespace is created on its first request and kept until the namespace is deleted.
+// The registry is read on every request, so that namespaces created or deleted by other replicas are found.
+type Manager struct {
+	logger *slog.Logger
+	// namespacer is nil if the store does not support namespaces.
+	namespacer storePkg.Namespacer
+	engine     *engine.Engine
+	engines    map[string]*namespaceEngine
+	// watch starts the store watcher of an engine while Watch is running.
+	watch func(*engine.Engine) context.CancelFunc
+	mu    sync.Mutex
+}
+
+// NewManager manages the namespaces of the store, whose default namespace is served by the engine.
+func NewManager(logger *slog.Logger, store storePkg.Store, engine *engine.Engine) *Manager {
+	namespacer, _ := store.(storePkg.Namespacer)
+	return &Manager{logger: logger, namespacer: namespacer, engine: engine, engines: make(map[string]*namespaceEngine)}
+}
+
+// DefaultEngine returns the engine of the default namespace.
+func (m *Manager) DefaultEngine() *engine.Engine {
+	return m.engine
+}
+
+// Engine returns the engine of the namespace, or that of the default namespace if the name is empty.
+func (m *Manager) Engine(ctx context.Context, name string) (*engine.Engine, error) {
+	if name == "" {
+		return m.engine, nil
+	}
+	if _, err := m.Read(ctx, name); err != nil {
+		if failure.Is(err, appError.ErrNotFound) {
+			m.forget(name)
+		}
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.engines[name]; ok {
+		return e.engine, nil
+	}
+	logger := m.logger.With(slog.String("namespace", name))
+	e := &namespaceEngine{engine: engine.New(engine.WithLogger(logger), engine.WithStore(m.namespacer.Namespace(name)))}
+	if m.watch != nil {
+		e.cancel = m.watch(e.engine)
+	}
+	m.engines[name] = e
+	return e.engine, nil
+}
+
+// LoadedEngine returns the engine of the namespace if a request has already loaded it, or that of the default namespace
+// if the name is empty. It neither reads the registry nor creates an engine, so that it can be used without a request.
+func (m *Manager) LoadedEngine(name string) (*engine.Engine, bool) {
+	if name == "" {
+		return m.engine, true
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.engines[name]
+	if !ok {
+		return nil, false
+	}
+	return e.engine, true
+}
+
+func (m *Manager) forget(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.engines[name]; ok {
+		if e.cancel != nil {
+			e.cancel()
+		}
+		delete(m.engines, name)
+	}
+}
+
+// Read returns the namespace from the registry.
+func (m *Manager) Read(ctx context.Context, name string) (*storePkg.Namespace, error) {
+	if err := m.supported(); err != nil {
+		return nil, err
+	}
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+	return m.namespacer.ReadNamespace(ctx, name)
+}
+
+// Create adds a namespace with an empty schema. Its keys are stored hashed, and are optional.
+func (m *Manager) Create(ctx context.Context, name string, presharedKey string, privilegedKey string) (*storePkg.Namespace, error) {
+	if err := m.supported(); err != nil {
+		return nil, err
+	}
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+	namespace := &storePkg.Namespace{
+		Name:              name,
+		PresharedKeyHash:  hashKey(presharedKey),
+		PrivilegedKeyHash: hashKey(privilegedKey),
+		CreatedAt:         time.Now().UTC(),
+	}
+	if err := m.namespacer.CreateNamespace(ctx, namespace); err != nil {
+		return nil, err
+	}
+	m.logger.Info("🏠 namespace created", slog.String("namespace", name))
+	return namespace, nil
+}
+
+func (m *Manager) List(ctx context.Context) ([]*storePkg.Namespace, error) {
+	if err := m.supported(); err != nil {
+		return nil, err
+	}
+	return m.namespacer.ReadNamespaces(ctx)
+}
+
+// Delete deletes the namespace with its schema and datasets.
+// Other replicas drop their engine of the namespace on its next request.
+func (m *Manager) Delete(ctx context.Context, name string) error {
+	if err := m.supported(); err != nil {
+		return err
+	}
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	if err := m.namespacer.DeleteNamespace(ctx, name); err != nil {
+		return err
+	}
+	m.forget(name)
+	m.logger.Info("🏚️ namespace deleted", slog.String("namespace", name))
+	return nil
+}
+
+func (m *Manager) supported() error {
+	if m.namespacer == nil {
+		return failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("the store does not support namespaces"))
+	}
+	return nil
+}
+
+// Watch runs the store watcher of the default namespace and of each namespace in use until the context is done,
+// so that the changes made by other replicas are followed.
+func (m *Manager) Watch(ctx context.Context, wg *sync.WaitGroup, interval time.Duration) {
+	defer wg.Done()
+	m.mu.Lock()
+	m.watch = func(e *engine.Engine) context.CancelFunc {
+		watchCtx, cancel := context.WithCancel(ctx)
+		wg.Add(1)
+		go e.Watch(watchCtx, wg, interval)
+		return cancel
+	}
+	m.watch(m.engine)
+	for _, e := range m.engines {
+		e.cancel = m.watch(e.engine)
+	}
+	m.mu.Unlock()
+
+	<-ctx.Done()
+
+	// The watchers are stopped by the context. No more are started, so that none is added to the wait group once done.
+	m.mu.Lock()
+	m.watch = nil
+	m.mu.Unlock()
+}
+
+func hashKey(key string) []byte {
+	if key == "" {
+		return nil
+	}
+	hash := sha256.Sum256([]byte(key))
+	return hash[:]
+}
@@ -0,0 +1,296 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/logger"
+	"github.com/shibukazu/open-ve/go/pkg/namespace"
+	pbValidate "github.com/shibukazu/open-ve/go/proto/validate/v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const maxNDJSONLineSize = 16 * 1024 * 1024
+
+// bulkCheckNDJSONMiddleware accepts newline-delimited JSON on the bulk check route.
+// Each request line holds the variables of one record and the validation ID is given by the id query parameter.
+// Each response line holds the result of one record, in the order of the records.
+// The records are evaluated through ValidateService.CheckStream as they are read, and each result is flushed as soon as
+// the results of the preceding records are written, so that neither the request nor the response is held in memory.
+// Lines that can't be decoded are reported as results with an error.
+func (g *Gateway) bulkCheckNDJSONMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/check/bulk" || r.Method != "POST" || !isNDJSON(r.Header.Get("Content-Type")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			err := failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("id query parameter is required"))
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			logger.LogError(g.logger, err)
+			return
+		}
+
+		eng, err := g.engine(r)
+		if err != nil {
+			http.Error(w, err.Error(), httpStatusFromError(err))
+			logger.LogError(g.logger, err)
+			return
+		}
+		variableNameToCELType, err := eng.VariableTypes(r.Context(), id)
+		if err != nil {
+			http.Error(w, err.Error(), httpStatusFromError(err))
+			logger.LogError(g.logger, err)
+			return
+		}
+
+		// The request is read while the response is written
+		rc := http.NewResponseController(w)
+		if err := rc.EnableFullDuplex(); err != nil && r.ProtoMajor == 1 {
+			err = failure.Translate(err, appError.ErrServerError, failure.Messagef("streaming is not supported"))
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			logger.LogError(g.logger, err)
+			return
+		}
+
+		ctx, cancel := context.WithCancel(metadata.NewOutgoingContext(r.Context(), g.outgoingMetadata(r)))
+		defer cancel()
+		stream, err := g.validateClient.CheckStream(ctx)
+		if err != nil {
+			http.Error(w, err.Error(), runtime.HTTPStatusFromCode(status.Code(err)))
+			logger.LogError(g.logger, err)
+			return
+		}
+
+		resultCh := make(chan *pbValidate.RecordResult)
+		// totalCh receives the number of records once the request is read
+		totalCh := make(chan int, 1)
+		errCh := make(chan error, 1)
+		go g.sendNDJSONRecords(ctx, r, id, variableNameToCELType, stream, resultCh, totalCh)
+		go func() {
+			for {
+				res, err := stream.Recv()
+				if err != nil {
+					errCh <- err
+					return
+				}
+				result := &pbValidate.RecordResult{Error: res.Error}
+				if index, err := strconv.Atoi(res.CorrelationId); err == nil {
+					result.Index = int32(index)
+				}
+				if res.Result != nil {
+					result.IsValid = res.Result.IsValid
+					result.Message = res.Result.Message
+					result.Errors = res.Result.Errors
+				}
+				select {
+				case resultCh <- result:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+
+		marshaler := protojson.MarshalOptions{EmitUnpopulated: true}
+		started := false
+		start := func() {
+			if !started {
+				w.Header().Del("Content-Length")
+				w.Header().Set("Content-Type", "application/x-ndjson")
+				w.WriteHeader(http.StatusOK)
+				started = true
+			}
+		}
+		pending := make(map[int32]*pbValidate.RecordResult)
+		var next int32
+		total := -1
+		for total < 0 || int(next) < total {
+			select {
+			case result := <-resultCh:
+				pending[result.Index] = result
+				for {
+					result, ok := pending[next]
+					if !ok {
+						break
+					}
+					delete(pending, next)
+					next++
+					line, err := marshaler.Marshal(result)
+					if err != nil {
+						g.logger.Error(failure.Translate(err, appError.ErrServerError, failure.Messagef("failed to encode result")).Error())
+						return
+					}
+					start()
+					if _, err := w.Write(append(line, '\n')); err != nil {
+						return
+					}
+					if err := rc.Flush(); err != nil {
+						return
+					}
+				}
+			case total = <-totalCh:
+			case err := <-errCh:
+				if err == io.EOF {
+					// No more results come from the stream, but those of undecodable lines may
+					errCh = nil
+					continue
+				}
+				if !started {
+					http.Error(w, err.Error(), runtime.HTTPStatusFromCode(status.Code(err)))
+				}
+				logger.LogError(g.logger, err)
+				return
+			case <-r.Context().Done():
+				return
+			}
+		}
+		start()
+	})
+}
+
+// sendNDJSONRecords sends each line of the request as a request of the stream, and reports the lines that can't be decoded as results.
+func (g *Gateway) sendNDJSONRecords(ctx context.Context, r *http.Request, id string, variableNameToCELType map[string]string, stream pbValidate.ValidateService_CheckStreamClient, resultCh chan<- *pbValidate.RecordResult, totalCh chan<- int) {
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true, Resolver: g.resolver}
+	prefix := typeURLPrefix(namespace.FromRequest(r))
+	index := 0
+	report := func(err error) bool {
+		select {
+		case resultCh <- &pbValidate.RecordResult{Index: int32(index), Error: err.Error()}:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 64*1024), maxNDJSONLineSize)
+	for ; scanner.Scan(); index++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			index--
+			continue
+		}
+		req, err := decodeNDJSONRecord(line, id, variableNameToCELType, prefix, unmarshaler)
+		if err != nil {
+			if !report(failure.Wrap(err, failure.Messagef("failed to decode record %d", index))) {
+				return
+			}
+			continue
+		}
+		req.CorrelationId = strconv.Itoa(index)
+		if err := stream.Send(req); err != nil {
+			// The error is received from the stream
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		// The remaining lines can't be read, which is reported as the result of the next record
+		err = failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("failed to read request body"))
+		logger.LogError(g.logger, err)
+		if !report(err) {
+			return
+		}
+		index++
+	}
+	if err := stream.CloseSend(); err != nil {
+		return
+	}
+	totalCh <- index
+}
+
+func decodeNDJSONRecord(line []byte, id string, variableNameToCELType map[string]string, typeURLPrefix string, unmarshaler protojson.UnmarshalOptions) (*pbValidate.CheckStreamRequest, error) {
+	var variables map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+	if err := decoder.Decode(&variables); err != nil {
+		return nil, failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("invalid JSON"))
+	}
+	convertedVariables, err := convertVariables(variables, variableNameToCELType, typeURLPrefix)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(map[string]interface{}{"id": id, "variables": convertedVariables})
+	if err != nil {
+		return nil, failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("failed to encode variables"))
+	}
+	validation := &pbValidate.Validation{}
+	if err := unmarshaler.Unmarshal(encoded, validation); err != nil {
+		return nil, failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("invalid variables"))
+	}
+	return &pbValidate.CheckStreamRequest{Validation: validation}, nil
+}
+
+func (g *Gateway) convertBulkCheckRequestBody(r *http.Request) error {
+	var body map[string]interface{}
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&body); err != nil {
+		return failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("failed to decode request body"))
+	}
+
+	id, ok := body["id"].(string)
+	if !ok {
+		return failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("id field is invalid"))
+	}
+	records, ok := body["records"].([]interface{})
+	if !ok {
+		return failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("records field is invalid"))
+	}
+
+	// Resolve variable types once for all records
+	eng, err := g.engine(r)
+	if err != nil {
+		return err
+	}
+	variableNameToCELType, err := eng.VariableTypes(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	for idx, record := range records {
+		record, ok := record.(map[string]interface{})
+		if !ok {
+			return failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("record %d is invalid", idx))
+		}
+		variables, ok := record["variables"].(map[string]interface{})
+		if !ok {
+			return failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("variables field of record %d is invalid", idx))
+		}
+		convertedVariables, err := convertVariables(variables, variableNameToCELType, typeURLPrefix(namespace.FromRequest(r)))
+		if err != nil {
+			return failure.Wrap(err, failure.Messagef("failed to convert variables of record %d", idx))
+		}
+		record["variables"] = convertedVariables
+		records[idx] = record
+	}
+	body["records"] = records
+
+	convertedBody, err := json.Marshal(body)
+	if err != nil {
+		return failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("failed to marshal request body"))
+	}
+	r.Body = io.NopCloser(bytes.NewBuffer(convertedBody))
+	r.ContentLength = int64(len(convertedBody))
+	return nil
+}
+
+func isNDJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/x-ndjson" || mediaType == "application/jsonl"
+}
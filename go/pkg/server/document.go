@@ -0,0 +1,63 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/logger"
+	pbValidate "github.com/shibukazu/open-ve/go/proto/validate/v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const documentCheckPathPrefix = "/v1/check/document/"
+
+// documentCheckRequestMiddleware sends the body of the document check route /v1/check/document/{id} as the JSON text
+// of the document, instead of letting the gateway decode it to a google.protobuf.Value whose numbers are doubles,
+// so that integers beyond 2^53 reach the variables as they are written.
+func (g *Gateway) documentCheckRequestMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, documentCheckPathPrefix) || r.Method != "POST" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		id := strings.TrimPrefix(r.URL.Path, documentCheckPathPrefix)
+		if id == "" {
+			err := failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("id path parameter is required"))
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			logger.LogError(g.logger, err)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			err = failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("failed to read request body"))
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			logger.LogError(g.logger, err)
+			return
+		}
+
+		req := &pbValidate.CheckDocumentRequest{Id: id, DocumentJson: string(body)}
+		res, err := g.validateClient.CheckDocument(metadata.NewOutgoingContext(r.Context(), g.outgoingMetadata(r)), req)
+		if err != nil {
+			http.Error(w, err.Error(), runtime.HTTPStatusFromCode(status.Code(err)))
+			logger.LogError(g.logger, err)
+			return
+		}
+		encoded, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(res)
+		if err != nil {
+			err = failure.Translate(err, appError.ErrServerError, failure.Messagef("failed to encode response"))
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			logger.LogError(g.logger, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(encoded)
+	})
+}
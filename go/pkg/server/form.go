@@ -0,0 +1,281 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/dsl/util"
+	"github.com/shibukazu/open-ve/go/pkg/logger"
+	"github.com/shibukazu/open-ve/go/pkg/namespace"
+	pbValidate "github.com/shibukazu/open-ve/go/proto/validate/v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const formCheckPathPrefix = "/v1/check/form/"
+
+// formCheckRequestMiddleware accepts multipart/form-data and application/x-www-form-urlencoded bodies
+// on the form check route /v1/check/form/{id}. Each field or file is mapped to the declared variable of the same name
+// and converted by its DSL type as it is read, then the request is checked as a single validation of /v1/check.
+// The profile and explain query parameters correspond to the fields of the check request.
+func (g *Gateway) formCheckRequestMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, formCheckPathPrefix) || r.Method != "POST" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		id := strings.TrimPrefix(r.URL.Path, formCheckPathPrefix)
+		if id == "" {
+			err := failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("id path parameter is required"))
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			logger.LogError(g.logger, err)
+			return
+		}
+
+		query := r.URL.Query()
+		explain := false
+		if query.Has("explain") {
+			var err error
+			explain, err = strconv.ParseBool(query.Get("explain"))
+			if err != nil {
+				err = failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("explain query parameter must be a boolean"))
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				logger.LogError(g.logger, err)
+				return
+			}
+		}
+
+		eng, err := g.engine(r)
+		if err != nil {
+			http.Error(w, err.Error(), httpStatusFromError(err))
+			logger.LogError(g.logger, err)
+			return
+		}
+		variableNameToCELType, err := eng.VariableTypes(r.Context(), id)
+		if err != nil {
+			http.Error(w, err.Error(), httpStatusFromError(err))
+			logger.LogError(g.logger, err)
+			return
+		}
+
+		form := &formVariables{
+			id:                    id,
+			variableNameToCELType: variableNameToCELType,
+			typeURLPrefix:         typeURLPrefix(namespace.FromRequest(r)),
+			variables:             make(map[string]*anypb.Any, len(variableNameToCELType)),
+		}
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		switch mediaType {
+		case "multipart/form-data":
+			err = g.readMultipartForm(w, r, form)
+		case "application/x-www-form-urlencoded":
+			err = g.readURLEncodedForm(w, r, form)
+		default:
+			err = failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("content type must be multipart/form-data or application/x-www-form-urlencoded"))
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+			logger.LogError(g.logger, err)
+			return
+		}
+		if err != nil {
+			status := http.StatusBadRequest
+			if failure.Is(err, appError.ErrRequestTooLarge) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			http.Error(w, err.Error(), status)
+			logger.LogError(g.logger, err)
+			return
+		}
+
+		req := &pbValidate.CheckRequest{
+			Validations: []*pbValidate.Validation{
+				{Id: id, Profile: query.Get("profile"), Variables: form.variables},
+			},
+			Explain: explain,
+		}
+		res, err := g.validateClient.Check(metadata.NewOutgoingContext(r.Context(), g.outgoingMetadata(r)), req)
+		if err != nil {
+			http.Error(w, err.Error(), runtime.HTTPStatusFromCode(status.Code(err)))
+			logger.LogError(g.logger, err)
+			return
+		}
+		body, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(res)
+		if err != nil {
+			err = failure.Translate(err, appError.ErrServerError, failure.Messagef("failed to encode response"))
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			logger.LogError(g.logger, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	})
+}
+
+// formVariables collects the variables of a form check request.
+type formVariables struct {
+	id                    string
+	variableNameToCELType map[string]string
+	typeURLPrefix         string
+	variables             map[string]*anypb.Any
+}
+
+// celType returns the type of the variable the field is mapped to.
+// It is called before the value of the field is read, so that undeclared and repeated fields are not read at all.
+func (f *formVariables) celType(name string) (string, error) {
+	celType, ok := f.variableNameToCELType[name]
+	if !ok {
+		return "", failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("field %s is not a variable of %s", name, f.id))
+	}
+	if _, ok := f.variables[name]; ok {
+		return "", failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("field %s is given more than once", name))
+	}
+	return celType, nil
+}
+
+// readMultipartForm streams the parts of the body. Each part is converted as soon as it is read,
+// so only one part within the size limit is kept in memory besides the converted variables.
+// As every part must be a distinct variable, the body is limited to a part per variable and one more for the boundaries and headers.
+func (g *Gateway) readMultipartForm(w http.ResponseWriter, r *http.Request, form *formVariables) error {
+	maxBodySize := int64(len(form.variableNameToCELType)+1) * g.httpConfig.FormMaxPartSize
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("failed to read multipart body"))
+	}
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				return failure.New(appError.ErrRequestTooLarge, failure.Messagef("multipart body exceeds %d bytes", maxBodySize))
+			}
+			return failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("failed to read multipart body"))
+		}
+		name := part.FormName()
+		if name == "" {
+			part.Close()
+			return failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("part without a field name is given"))
+		}
+		celType, err := form.celType(name)
+		if err != nil {
+			part.Close()
+			return err
+		}
+		value, err := io.ReadAll(io.LimitReader(part, g.httpConfig.FormMaxPartSize+1))
+		part.Close()
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				return failure.New(appError.ErrRequestTooLarge, failure.Messagef("multipart body exceeds %d bytes", maxBodySize))
+			}
+			return failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("failed to read field %s", name))
+		}
+		if int64(len(value)) > g.httpConfig.FormMaxPartSize {
+			return failure.New(appError.ErrRequestTooLarge, failure.Messagef("field %s exceeds %d bytes", name, g.httpConfig.FormMaxPartSize))
+		}
+		form.variables[name], err = g.convertFormValue(name, value, celType, form.typeURLPrefix)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readURLEncodedForm reads the body, which may be as large as the size limit of a part.
+func (g *Gateway) readURLEncodedForm(w http.ResponseWriter, r *http.Request, form *formVariables) error {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, g.httpConfig.FormMaxPartSize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return failure.New(appError.ErrRequestTooLarge, failure.Messagef("form body exceeds %d bytes", g.httpConfig.FormMaxPartSize))
+		}
+		return failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("failed to read form body"))
+	}
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("failed to decode form body"))
+	}
+	for name, value := range values {
+		celType, err := form.celType(name)
+		if err != nil {
+			return err
+		}
+		if len(value) > 1 {
+			return failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("field %s is given more than once", name))
+		}
+		form.variables[name], err = g.convertFormValue(name, []byte(value[0]), celType, form.typeURLPrefix)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convertFormValue converts the text of a field, or the content of a file, to the protobuf value of the variable type.
+// Bytes are kept as they are instead of being encoded in base64 as in the JSON check request.
+func (g *Gateway) convertFormValue(name string, value []byte, celType string, typeURLPrefix string) (*anypb.Any, error) {
+	invalid := func(err error) error {
+		return failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("field %s must be %s", name, celType))
+	}
+	var msg proto.Message
+	switch celType {
+	case "int":
+		v, err := strconv.ParseInt(string(value), 10, 64)
+		if err != nil {
+			return nil, invalid(err)
+		}
+		msg = wrapperspb.Int64(v)
+	case "uint":
+		v, err := strconv.ParseUint(string(value), 10, 64)
+		if err != nil {
+			return nil, invalid(err)
+		}
+		msg = wrapperspb.UInt64(v)
+	case "double":
+		v, err := strconv.ParseFloat(string(value), 64)
+		if err != nil {
+			return nil, invalid(err)
+		}
+		msg = wrapperspb.Double(v)
+	case "bool":
+		v, err := strconv.ParseBool(string(value))
+		if err != nil {
+			return nil, invalid(err)
+		}
+		msg = wrapperspb.Bool(v)
+	case "bytes":
+		msg = wrapperspb.Bytes(value)
+	case "string", util.TypeDecimal:
+		msg = wrapperspb.String(string(value))
+	default:
+		// Messages are given as JSON objects in the protobuf JSON mapping
+		messageType, err := g.resolver.FindMessageByURL(typeURLPrefix + celType)
+		if err != nil {
+			return nil, failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("message type %s of field %s is not registered", celType, name))
+		}
+		m := messageType.New().Interface()
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true, Resolver: g.resolver}).Unmarshal(value, m); err != nil {
+			return nil, invalid(err)
+		}
+		msg = m
+	}
+	v, err := anypb.New(msg)
+	if err != nil {
+		return nil, failure.Translate(err, appError.ErrServerError, failure.Messagef("failed to encode field %s", name))
+	}
+	return v, nil
+}
@@ -0,0 +1,170 @@
+package server
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/authn"
+	"github.com/shibukazu/open-ve/go/pkg/logger"
+	"github.com/shibukazu/open-ve/go/pkg/namespace"
+	"github.com/shibukazu/open-ve/go/pkg/slave"
+	"google.golang.org/grpc/metadata"
+)
+
+// forwardSingleIDRequestMiddleware forwards the whole request of a route that targets a single validation ID
+// (bulk check, document check and form check) to the slave node when the validation ID is not registered on the master.
+func (g *Gateway) forwardSingleIDRequestMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		isBulk := r.URL.Path == "/v1/check/bulk"
+		isDocument := strings.HasPrefix(r.URL.Path, "/v1/check/document/")
+		isForm := strings.HasPrefix(r.URL.Path, "/v1/check/form/")
+		if g.mode != "master" || namespace.FromRequest(r) != "" || r.Method != "POST" || (!isBulk && !isDocument && !isForm) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Only the JSON bulk check has the ID in its body. The other bodies are forwarded as they are read
+		var id string
+		explain := false
+		switch {
+		case isBulk && isNDJSON(r.Header.Get("Content-Type")):
+			id = r.URL.Query().Get("id")
+		case isBulk:
+			reqBody, err := io.ReadAll(r.Body)
+			if err != nil {
+				err = failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("failed to read request body"))
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				logger.LogError(g.logger, err)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewBuffer(reqBody))
+			var body struct {
+				Id string `json:"id"`
+			}
+			if err := json.Unmarshal(reqBody, &body); err != nil {
+				err = failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("failed to decode request body"))
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				logger.LogError(g.logger, err)
+				return
+			}
+			id = body.Id
+		case isDocument:
+			id = strings.TrimPrefix(r.URL.Path, "/v1/check/document/")
+		default:
+			id = strings.TrimPrefix(r.URL.Path, "/v1/check/form/")
+			// Invalid values are rejected by the slave
+			explain, _ = strconv.ParseBool(r.URL.Query().Get("explain"))
+		}
+		if explain {
+			if err := g.authorizeExplain(r); err != nil {
+				http.Error(w, err.Error(), httpStatusFromError(err))
+				logger.LogError(g.logger, err)
+				return
+			}
+		}
+
+		registered, err := g.namespaces.DefaultEngine().HasValidation(r.Context(), id)
+		if err != nil {
+			http.Error(w, err.Error(), httpStatusFromError(err))
+			logger.LogError(g.logger, err)
+			return
+		}
+		if registered {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		slaveNode, err := g.slaveManager.FindSlave(id)
+		if err != nil {
+			http.Error(w, err.Error(), httpStatusFromError(err))
+			logger.LogError(g.logger, err)
+			return
+		}
+
+		// The slave streams the results of NDJSON bulk checks while the request is forwarded
+		rc := http.NewResponseController(w)
+		_ = rc.EnableFullDuplex()
+
+		// The response is not bounded by a timeout as a stream may last long. The request ends with the incoming one
+		transport := &http.Transport{ResponseHeaderTimeout: 30 * time.Second}
+		if slaveNode.TLSEnabled {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		client := &http.Client{Transport: transport}
+		defer transport.CloseIdleConnections()
+
+		req, err := http.NewRequestWithContext(r.Context(), "POST", slaveNode.Addr+r.URL.RequestURI(), r.Body)
+		if err != nil {
+			err = failure.Translate(err, appError.ErrRequestForwardFailed, failure.Messagef("failed to create forward request"))
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			logger.LogError(g.logger, err)
+			return
+		}
+		req.ContentLength = r.ContentLength
+		req.Header.Set("Content-Type", r.Header.Get("Content-Type"))
+
+		switch slaveNode.Authn.Method {
+		case "preshared":
+			req.Header.Set("Authorization", "Bearer "+slaveKey(slaveNode, explain))
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			err = failure.Translate(err, appError.ErrRequestForwardFailed, failure.Messagef("failed to forward request to slave id:%s", id))
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			logger.LogError(g.logger, err)
+			return
+		}
+		defer resp.Body.Close()
+
+		w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+		w.WriteHeader(resp.StatusCode)
+		if _, err := io.Copy(flushWriter{w: w, rc: rc}, resp.Body); err != nil {
+			g.logger.Error(failure.Translate(err, appError.ErrServerError, failure.Messagef("failed to write response")).Error())
+			return
+		}
+		g.logger.Info(fmt.Sprintf("⚽️ Request %s (id:%s) Forwarded to Slave %s", r.URL.Path, id, slaveNode.Id))
+	})
+}
+
+// authorizeExplain checks that the client is authenticated and granted the explain permission on the master,
+// since the slaves are sent the keys of the master instead of those of the client.
+func (g *Gateway) authorizeExplain(r *http.Request) error {
+	ctx := metadata.NewIncomingContext(r.Context(), g.outgoingMetadata(r))
+	if _, err := g.authenticator.Authenticate(ctx); err != nil {
+		return err
+	}
+	return g.authenticator.Authorize(ctx, authn.PermissionExplain)
+}
+
+// slaveKey returns the key a request is forwarded to the slave with, its privileged key if the request asks for explain.
+// Without a privileged key, the slave denies explain to the master as to any client.
+func slaveKey(slaveNode *slave.Slave, explain bool) string {
+	if explain && slaveNode.Authn.Preshared.PrivilegedKey != "" {
+		return slaveNode.Authn.Preshared.PrivilegedKey
+	}
+	return slaveNode.Authn.Preshared.Key
+}
+
+// flushWriter flushes each write, so that the responses streamed by slaves are passed on as they arrive.
+type flushWriter struct {
+	w  io.Writer
+	rc *http.ResponseController
+}
+
+func (f flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+	return n, f.rc.Flush()
+}
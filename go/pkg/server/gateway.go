@@ -9,6 +9,7 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -16,17 +17,23 @@ import (
 	"github.com/morikuni/failure/v2"
 	"github.com/rs/cors"
 	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/appError/grpcError"
+	"github.com/shibukazu/open-ve/go/pkg/authn"
 	"github.com/shibukazu/open-ve/go/pkg/config"
-	"github.com/shibukazu/open-ve/go/pkg/dsl/reader"
+	"github.com/shibukazu/open-ve/go/pkg/dsl/util"
 	"github.com/shibukazu/open-ve/go/pkg/logger"
+	"github.com/shibukazu/open-ve/go/pkg/namespace"
 	"github.com/shibukazu/open-ve/go/pkg/slave"
 	pbDSL "github.com/shibukazu/open-ve/go/proto/dsl/v1"
+	pbNamespace "github.com/shibukazu/open-ve/go/proto/namespace/v1"
 	pbSlave "github.com/shibukazu/open-ve/go/proto/slave/v1"
 	pbValidate "github.com/shibukazu/open-ve/go/proto/validate/v1"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	pbHealth "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
 )
 
 type Gateway struct {
@@ -34,9 +41,15 @@ type Gateway struct {
 	httpConfig   *config.HttpConfig
 	gRPCConfig   *config.GRPCConfig
 	logger       *slog.Logger
-	dslReader    *reader.DSLReader
+	namespaces   *namespace.Manager
 	slaveManager *slave.SlaveManager
-	server       *http.Server
+	// authenticator authorizes explain on the master for the checks forwarded to slaves.
+	authenticator authn.Authenticator
+	server        *http.Server
+	// dslClient and validateClient call the gRPC server for the routes served apart from the gateway mux.
+	dslClient      pbDSL.DSLServiceClient
+	validateClient pbValidate.ValidateServiceClient
+	resolver       *messageTypeResolver
 }
 
 func NewGateway(
@@ -44,16 +57,18 @@ func NewGateway(
 	httpConfig *config.HttpConfig,
 	gRPCConfig *config.GRPCConfig,
 	logger *slog.Logger,
-	dslReader *reader.DSLReader,
+	namespaces *namespace.Manager,
 	slaveManager *slave.SlaveManager,
+	authenticator authn.Authenticator,
 ) *Gateway {
 	return &Gateway{
-		mode:         mode,
-		httpConfig:   httpConfig,
-		gRPCConfig:   gRPCConfig,
-		logger:       logger,
-		dslReader:    dslReader,
-		slaveManager: slaveManager,
+		mode:          mode,
+		httpConfig:    httpConfig,
+		gRPCConfig:    gRPCConfig,
+		logger:        logger,
+		namespaces:    namespaces,
+		slaveManager:  slaveManager,
+		authenticator: authenticator,
 	}
 }
 
@@ -78,10 +93,20 @@ func (g *Gateway) Run(ctx context.Context, wg *sync.WaitGroup) {
 		panic(failure.Translate(err, appError.ErrServerError, failure.Messagef("failed to dial gRPC server")))
 	}
 	defer conn.Close()
+	g.dslClient = pbDSL.NewDSLServiceClient(conn)
+	g.validateClient = pbValidate.NewValidateServiceClient(conn)
 
 	runtime.DefaultContextTimeout = 10 * time.Second
+	g.resolver = &messageTypeResolver{namespaces: g.namespaces}
 	muxOpts := []runtime.ServeMuxOption{
 		runtime.WithHealthzEndpoint(pbHealth.NewHealthClient(conn)),
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.HTTPBodyMarshaler{
+			Marshaler: &runtime.JSONPb{
+				MarshalOptions:   protojson.MarshalOptions{EmitUnpopulated: true, Resolver: g.resolver},
+				UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true, Resolver: g.resolver},
+			},
+		}),
+		runtime.WithIncomingHeaderMatcher(g.incomingHeaderMatcher),
 	}
 	grpcGateway := runtime.NewServeMux(muxOpts...)
 
@@ -93,18 +118,23 @@ func (g *Gateway) Run(ctx context.Context, wg *sync.WaitGroup) {
 		panic(failure.Translate(err, appError.ErrServerError, failure.Messagef("failed to register dsl service on gateway")))
 	}
 
+	if err := pbNamespace.RegisterNamespaceServiceHandlerFromEndpoint(ctx, grpcGateway, ":"+g.gRPCConfig.Port, dialOpts); err != nil {
+		panic(failure.Translate(err, appError.ErrServerError, failure.Messagef("failed to register namespace service on gateway")))
+	}
+
 	if g.mode == "master" {
 		if err := pbSlave.RegisterSlaveServiceHandlerFromEndpoint(ctx, grpcGateway, ":"+g.gRPCConfig.Port, dialOpts); err != nil {
 			panic(failure.Translate(err, appError.ErrServerError, failure.Messagef("failed to register slave service on gateway")))
 		}
 	}
 
-	withMiddleware := g.forwardCheckRequestMiddleware(g.validateRequestTypeConvertMiddleware(grpcGateway))
+	withMiddleware := g.namespacePathMiddleware(g.watchDSLMiddleware(g.dslETagMiddleware(g.forwardSingleIDRequestMiddleware(g.bulkCheckNDJSONMiddleware(g.formCheckRequestMiddleware(g.documentCheckRequestMiddleware(g.forwardCheckRequestMiddleware(g.validateRequestTypeConvertMiddleware(grpcGateway)))))))))
 
 	withCors := cors.New(cors.Options{
 		AllowedOrigins:   g.httpConfig.CORSAllowedOrigins,
 		AllowedHeaders:   g.httpConfig.CORSAllowedHeaders,
 		AllowedMethods:   []string{"GET", "POST", "PATCH", "PUT", "DELETE", "OPTIONS"},
+		ExposedHeaders:   []string{"ETag"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}).Handler(withMiddleware)
@@ -164,16 +194,24 @@ func (rec *responseRecorder) Write(b []byte) (int, error) {
 	return rec.body.Write(b)
 }
 
+// httpStatusFromError returns the HTTP status of the error, the same as the gateway returns for its gRPC status.
+func httpStatusFromError(err error) int {
+	return runtime.HTTPStatusFromCode(status.Code(grpcError.From(err)))
+}
+
 func (g *Gateway) forwardCheckRequestMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if g.mode == "master" && r.URL.Path == "/v1/check" && r.Method == "POST" {
+		// Slaves register the schemas of their default namespace only, so the requests of other namespaces are not forwarded
+		if g.mode == "master" && namespace.FromRequest(r) == "" && r.URL.Path == "/v1/check" && r.Method == "POST" {
 			ctx := context.Background()
 			modifiedRequestValidations := make([]interface{}, 0)
 			validationResults := make([]interface{}, 0)
 
 			var reqBody map[string]interface{}
 			var resBody map[string]interface{}
-			if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			decoder := json.NewDecoder(r.Body)
+			decoder.UseNumber()
+			if err := decoder.Decode(&reqBody); err != nil {
 				err = failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("failed to decode request body"))
 				http.Error(w, err.Error(), http.StatusBadRequest)
 				logger.LogError(g.logger, err)
@@ -188,10 +226,21 @@ func (g *Gateway) forwardCheckRequestMiddleware(next http.Handler) http.Handler
 				return
 			}
 
-			dslFound := false
-			dsl, err := g.dslReader.Read(ctx)
-			if err == nil {
-				dslFound = true
+			explain := false
+			if value, ok := reqBody["explain"]; ok {
+				if explain, ok = value.(bool); !ok {
+					err := failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("explain field is invalid"))
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					logger.LogError(g.logger, err)
+					return
+				}
+			}
+			if explain {
+				if err := g.authorizeExplain(r); err != nil {
+					http.Error(w, err.Error(), httpStatusFromError(err))
+					logger.LogError(g.logger, err)
+					return
+				}
 			}
 
 			ch := make(chan []interface{})
@@ -214,17 +263,14 @@ func (g *Gateway) forwardCheckRequestMiddleware(next http.Handler) http.Handler
 				}
 
 				// Check if the request forward is needed
-				isForwardNeed := true
-				if dslFound {
-					for _, validation := range dsl.Validations {
-						if validation.ID == id {
-							isForwardNeed = false
-							break
-						}
-					}
+				registered, err := g.namespaces.DefaultEngine().HasValidation(ctx, id)
+				if err != nil {
+					http.Error(w, err.Error(), httpStatusFromError(err))
+					logger.LogError(g.logger, err)
+					return
 				}
 
-				if isForwardNeed {
+				if !registered {
 					numForwarded++
 					go func(id string, ch chan []interface{}) {
 						// Find the slave node that can handle validation ID
@@ -248,6 +294,9 @@ func (g *Gateway) forwardCheckRequestMiddleware(next http.Handler) http.Handler
 						reqBody := map[string]interface{}{
 							"validations": []interface{}{validation},
 						}
+						if explain {
+							reqBody["explain"] = true
+						}
 						body, err := json.Marshal(reqBody)
 						if err != nil {
 							errCh <- failure.Translate(err, appError.ErrRequestForwardFailed, failure.Messagef("failed to marshal request body"))
@@ -259,10 +308,15 @@ func (g *Gateway) forwardCheckRequestMiddleware(next http.Handler) http.Handler
 							return
 						}
 						req.Header.Set("Content-Type", "application/json")
+						for _, key := range g.gRPCConfig.ContextMetadata {
+							if value := r.Header.Get(key); value != "" {
+								req.Header.Set(key, value)
+							}
+						}
 
 						switch slaveNode.Authn.Method {
 						case "preshared":
-							req.Header.Set("Authorization", "Bearer "+slaveNode.Authn.Preshared.Key)
+							req.Header.Set("Authorization", "Bearer "+slaveKey(slaveNode, explain))
 						}
 
 						resp, err := client.Do(req)
@@ -272,7 +326,15 @@ func (g *Gateway) forwardCheckRequestMiddleware(next http.Handler) http.Handler
 						}
 						defer resp.Body.Close()
 
-						if resp.StatusCode != http.StatusOK {
+						switch resp.StatusCode {
+						case http.StatusOK:
+						case http.StatusUnauthorized:
+							errCh <- failure.New(appError.ErrAuthenticationFailed, failure.Messagef("slave id:%s rejected the request: %s", id, resp.Status))
+							return
+						case http.StatusForbidden:
+							errCh <- failure.New(appError.ErrPermissionDenied, failure.Messagef("slave id:%s rejected the request: %s", id, resp.Status))
+							return
+						default:
 							errCh <- failure.New(appError.ErrRequestForwardFailed, failure.Messagef("failed to forward request to slave id:%s", id))
 							return
 						}
@@ -298,7 +360,7 @@ func (g *Gateway) forwardCheckRequestMiddleware(next http.Handler) http.Handler
 			for i := 0; i < numForwarded; i++ {
 				select {
 				case err := <-errCh:
-					http.Error(w, err.Error(), http.StatusInternalServerError)
+					http.Error(w, err.Error(), httpStatusFromError(err))
 					logger.LogError(g.logger, err)
 					return
 				case results := <-ch:
@@ -327,6 +389,15 @@ func (g *Gateway) forwardCheckRequestMiddleware(next http.Handler) http.Handler
 			}
 			next.ServeHTTP(rec, r)
 
+			// Pass through errors (e.g. authentication or permission errors) as is
+			if rec.statusCode != 0 && rec.statusCode != http.StatusOK {
+				w.WriteHeader(rec.statusCode)
+				if _, err := w.Write(rec.body.Bytes()); err != nil {
+					g.logger.Error(failure.Translate(err, appError.ErrServerError, failure.Messagef("failed to write response")).Error())
+				}
+				return
+			}
+
 			// Concat the validation results
 			if err := json.Unmarshal(rec.body.Bytes(), &resBody); err != nil {
 				err = failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("failed to decode response body"))
@@ -367,7 +438,10 @@ func (g *Gateway) validateRequestTypeConvertMiddleware(next http.Handler) http.H
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/v1/check" && r.Method == "POST" {
 			var body map[string]interface{}
-			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			// Numbers are kept as they are written so that integers are not rounded through float64
+			decoder := json.NewDecoder(r.Body)
+			decoder.UseNumber()
+			if err := decoder.Decode(&body); err != nil {
 				err = failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("failed to decode request body"))
 				http.Error(w, err.Error(), http.StatusBadRequest)
 				logger.LogError(g.logger, err)
@@ -382,6 +456,13 @@ func (g *Gateway) validateRequestTypeConvertMiddleware(next http.Handler) http.H
 				return
 			}
 
+			eng, err := g.engine(r)
+			if err != nil {
+				http.Error(w, err.Error(), httpStatusFromError(err))
+				logger.LogError(g.logger, err)
+				return
+			}
+
 			for idx, validation := range validations {
 				validation, ok := validation.(map[string]interface{})
 				if !ok {
@@ -407,27 +488,18 @@ func (g *Gateway) validateRequestTypeConvertMiddleware(next http.Handler) http.H
 					return
 				}
 
-				variableNameToCELType, err := g.dslReader.GetVariableNameToCELType(context.Background(), id)
+				variableNameToCELType, err := eng.VariableTypes(r.Context(), id)
 				if err != nil {
-					http.Error(w, err.Error(), http.StatusInternalServerError)
+					http.Error(w, err.Error(), httpStatusFromError(err))
 					logger.LogError(g.logger, err)
 					return
 				}
 
-				convertedVariables := make(map[string]interface{}, len(variables))
-				for key, value := range variables {
-					celType := variableNameToCELType[key]
-					convertedType, err := convertCELTypeToGoogleProtobufType(celType)
-					if err != nil {
-						http.Error(w, err.Error(), http.StatusBadRequest)
-						logger.LogError(g.logger, err)
-						return
-					}
-					variable := make(map[string]interface{}, 2)
-					variable["@type"] = convertedType
-					variable["value"] = value
-
-					convertedVariables[key] = variable
+				convertedVariables, err := convertVariables(variables, variableNameToCELType, typeURLPrefix(namespace.FromRequest(r)))
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					logger.LogError(g.logger, err)
+					return
 				}
 				validation["variables"] = convertedVariables
 
@@ -444,11 +516,52 @@ func (g *Gateway) validateRequestTypeConvertMiddleware(next http.Handler) http.H
 
 			r.Body = io.NopCloser(bytes.NewBuffer(convertedBody))
 			r.ContentLength = int64(len(convertedBody))
+		} else if r.URL.Path == "/v1/check/bulk" && r.Method == "POST" {
+			if err := g.convertBulkCheckRequestBody(r); err != nil {
+				http.Error(w, err.Error(), httpStatusFromError(err))
+				logger.LogError(g.logger, err)
+				return
+			}
 		}
 		next.ServeHTTP(w, r)
 	})
 }
 
+func convertVariables(variables map[string]interface{}, variableNameToCELType map[string]string, typeURLPrefix string) (map[string]interface{}, error) {
+	convertedVariables := make(map[string]interface{}, len(variables))
+	for key, value := range variables {
+		celType := variableNameToCELType[key]
+		if celType != "" && !util.IsPrimitiveType(celType) {
+			// Messages are given as objects in the protobuf JSON mapping
+			fields, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("variable %s must be an object of %s", key, celType))
+			}
+			variable := make(map[string]interface{}, len(fields)+1)
+			for name, field := range fields {
+				variable[name] = field
+			}
+			variable["@type"] = typeURLPrefix + celType
+			convertedVariables[key] = variable
+			continue
+		}
+		// Decimals are given as strings so that they are not rounded as JSON numbers
+		if _, ok := value.(string); celType == util.TypeDecimal && !ok {
+			return nil, failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("variable %s must be a decimal string", key))
+		}
+		convertedType, err := convertCELTypeToGoogleProtobufType(celType)
+		if err != nil {
+			return nil, err
+		}
+		variable := make(map[string]interface{}, 2)
+		variable["@type"] = convertedType
+		variable["value"] = value
+
+		convertedVariables[key] = variable
+	}
+	return convertedVariables, nil
+}
+
 func convertCELTypeToGoogleProtobufType(celType string) (string, error) {
 	switch celType {
 	case "int":
@@ -459,11 +572,24 @@ func convertCELTypeToGoogleProtobufType(celType string) (string, error) {
 		return "type.googleapis.com/google.protobuf.DoubleValue", nil
 	case "bool":
 		return "type.googleapis.com/google.protobuf.BoolValue", nil
-	case "string":
+	case "string", util.TypeDecimal:
 		return "type.googleapis.com/google.protobuf.StringValue", nil
 	case "bytes":
 		return "type.googleapis.com/google.protobuf.BytesValue", nil
 	default:
-		return "", failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("unsupported variable type: %s\nplease specify one of the following types: int, uint, double, bool, string, bytes", celType))
+		return "", failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("unsupported variable type: %s\nplease specify one of the following types: int, uint, double, bool, string, bytes, decimal", celType))
+	}
+}
+
+// incomingHeaderMatcher forwards the namespace and the headers exposed to the rules as request metadata in addition to the default ones.
+func (g *Gateway) incomingHeaderMatcher(key string) (string, bool) {
+	if strings.EqualFold(key, namespace.Header) {
+		return namespace.MetadataKey, true
+	}
+	for _, contextMetadata := range g.gRPCConfig.ContextMetadata {
+		if strings.EqualFold(key, contextMetadata) {
+			return strings.ToLower(key), true
+		}
 	}
+	return runtime.DefaultHeaderMatcher(key)
 }
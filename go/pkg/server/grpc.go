@@ -11,20 +11,26 @@ import (
 	grpcauth "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
 	"github.com/morikuni/failure/v2"
 	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/appError/grpcError"
 	"github.com/shibukazu/open-ve/go/pkg/authn"
 	"github.com/shibukazu/open-ve/go/pkg/config"
-	"github.com/shibukazu/open-ve/go/pkg/dsl/reader"
+	"github.com/shibukazu/open-ve/go/pkg/dsl/bundle"
+	"github.com/shibukazu/open-ve/go/pkg/namespace"
 	svcDSL "github.com/shibukazu/open-ve/go/pkg/services/dsl/v1"
 	svcHealth "github.com/shibukazu/open-ve/go/pkg/services/health/v1"
+	svcNamespace "github.com/shibukazu/open-ve/go/pkg/services/namespace/v1"
 	svcSlave "github.com/shibukazu/open-ve/go/pkg/services/slave/v1"
 	svcValidate "github.com/shibukazu/open-ve/go/pkg/services/validate/v1"
 	"github.com/shibukazu/open-ve/go/pkg/slave"
-	"github.com/shibukazu/open-ve/go/pkg/validator"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
 
 	pbDSL "github.com/shibukazu/open-ve/go/proto/dsl/v1"
+	pbNamespace "github.com/shibukazu/open-ve/go/proto/namespace/v1"
 	pbSlave "github.com/shibukazu/open-ve/go/proto/slave/v1"
 	pbValidate "github.com/shibukazu/open-ve/go/proto/validate/v1"
 	pbHealth "google.golang.org/grpc/health/grpc_health_v1"
@@ -32,11 +38,11 @@ import (
 
 type GRPC struct {
 	mode           string
-	dslReader      *reader.DSLReader
-	validator      *validator.Validator
+	namespaces     *namespace.Manager
 	slaveManager   *slave.SlaveManager
 	slaveRegistrar *slave.SlaveRegistrar
 	authenticator  authn.Authenticator
+	bundleSigner   *bundle.Signer
 	gRPCConfig     *config.GRPCConfig
 	logger         *slog.Logger
 	server         *grpc.Server
@@ -46,19 +52,19 @@ func NewGrpc(
 	mode string,
 	gRPCConfig *config.GRPCConfig,
 	logger *slog.Logger,
-	validator *validator.Validator,
-	dslReader *reader.DSLReader,
+	namespaces *namespace.Manager,
 	slaveManager *slave.SlaveManager,
 	slaveRegistrar *slave.SlaveRegistrar,
 	authenticator authn.Authenticator,
+	bundleSigner *bundle.Signer,
 ) *GRPC {
 	return &GRPC{
 		mode:           mode,
-		validator:      validator,
-		dslReader:      dslReader,
+		namespaces:     namespaces,
 		slaveManager:   slaveManager,
 		slaveRegistrar: slaveRegistrar,
 		authenticator:  authenticator,
+		bundleSigner:   bundleSigner,
 		gRPCConfig:     gRPCConfig,
 		logger:         logger,
 	}
@@ -76,6 +82,10 @@ func (g *GRPC) Run(ctx context.Context, wg *sync.WaitGroup, mode string) {
 		g.accessLogInterceptor(),
 		g.authnInterceptor(),
 	}...))
+	grpcServerOpts = append(grpcServerOpts, grpc.ChainStreamInterceptor([]grpc.StreamServerInterceptor{
+		g.accessLogStreamInterceptor(),
+		g.authnStreamInterceptor(),
+	}...))
 	if g.gRPCConfig.TLS.Enabled {
 		if g.gRPCConfig.TLS.CertPath == "" || g.gRPCConfig.TLS.KeyPath == "" {
 			panic(failure.New(appError.ErrServerError, failure.Message("certPath and keyPath must be set")))
@@ -89,12 +99,15 @@ func (g *GRPC) Run(ctx context.Context, wg *sync.WaitGroup, mode string) {
 
 	g.server = grpc.NewServer(grpcServerOpts...)
 
-	validateService := svcValidate.NewService(ctx, g.logger, g.validator)
+	validateService := svcValidate.NewService(ctx, g.logger, g.namespaces, g.authenticator, g.gRPCConfig.StreamConcurrency, g.gRPCConfig.ContextMetadata)
 	pbValidate.RegisterValidateServiceServer(g.server, validateService)
 
-	dslService := svcDSL.NewService(ctx, g.logger, mode, g.dslReader, g.slaveRegistrar)
+	dslService := svcDSL.NewService(ctx, g.logger, mode, g.namespaces, g.slaveRegistrar, g.bundleSigner)
 	pbDSL.RegisterDSLServiceServer(g.server, dslService)
 
+	namespaceService := svcNamespace.NewService(ctx, g.logger, g.namespaces)
+	pbNamespace.RegisterNamespaceServiceServer(g.server, namespaceService)
+
 	healthService := svcHealth.NewService(ctx)
 	pbHealth.RegisterHealthServer(g.server, healthService)
 
@@ -143,21 +156,89 @@ func (g *GRPC) shutdown(ctx context.Context) {
 
 func (g *GRPC) accessLogInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		g.logger.Info("🔍 Access Log", slog.String("Method", info.FullMethod), slog.String("Request", fmt.Sprintf("%+v", req)))
+		g.logger.Info("🔍 Access Log", slog.String("Method", info.FullMethod), slog.String("Request", redactRequest(req)))
 
 		resp, err := handler(ctx, req)
 		return resp, err
 	}
 }
 
-func (g *GRPC) authnInterceptor() grpc.UnaryServerInterceptor {
-	return grpcauth.UnaryServerInterceptor(
-		func(ctx context.Context) (context.Context, error) {
-			_, err := g.authenticator.Authenticate(ctx)
-			if err != nil {
-				return nil, err
+func (g *GRPC) accessLogStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		g.logger.Info("🔍 Access Log", slog.String("Method", info.FullMethod), slog.String("Stream", "opened"))
+
+		err := handler(srv, &accessLogServerStream{ServerStream: ss, method: info.FullMethod, logger: g.logger})
+		g.logger.Info("🔍 Access Log", slog.String("Method", info.FullMethod), slog.String("Stream", "closed"))
+		return err
+	}
+}
+
+type accessLogServerStream struct {
+	grpc.ServerStream
+	method string
+	logger *slog.Logger
+}
+
+func (s *accessLogServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.logger.Info("🔍 Access Log", slog.String("Method", s.method), slog.String("Request", redactRequest(m)))
+	}
+	return err
+}
+
+// redactRequest formats the request for the access log with the fields marked debug_redact, such as keys, cleared.
+func redactRequest(req interface{}) string {
+	m, ok := req.(proto.Message)
+	if !ok {
+		return fmt.Sprintf("%+v", req)
+	}
+	m = proto.Clone(m)
+	redactMessage(m.ProtoReflect())
+	return fmt.Sprintf("%+v", m)
+}
+
+func redactMessage(m protoreflect.Message) {
+	var redacted []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if options, ok := fd.Options().(*descriptorpb.FieldOptions); ok && options.GetDebugRedact() {
+			redacted = append(redacted, fd)
+			return true
+		}
+		switch {
+		case fd.IsList() && fd.Message() != nil:
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				redactMessage(list.Get(i).Message())
 			}
-			return ctx, nil
-		},
-	)
+		case fd.IsMap() && fd.MapValue().Message() != nil:
+			v.Map().Range(func(_ protoreflect.MapKey, value protoreflect.Value) bool {
+				redactMessage(value.Message())
+				return true
+			})
+		case !fd.IsList() && !fd.IsMap() && fd.Message() != nil:
+			redactMessage(v.Message())
+		}
+		return true
+	})
+	// Fields are not cleared while ranging over them
+	for _, fd := range redacted {
+		m.Clear(fd)
+	}
+}
+
+func (g *GRPC) authnInterceptor() grpc.UnaryServerInterceptor {
+	return grpcauth.UnaryServerInterceptor(g.authenticate)
+}
+
+func (g *GRPC) authnStreamInterceptor() grpc.StreamServerInterceptor {
+	return grpcauth.StreamServerInterceptor(g.authenticate)
+}
+
+func (g *GRPC) authenticate(ctx context.Context) (context.Context, error) {
+	principal, err := g.authenticator.Authenticate(ctx)
+	if err != nil {
+		return nil, grpcError.From(err)
+	}
+	return authn.WithPrincipal(ctx, principal), nil
 }
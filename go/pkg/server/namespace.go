@@ -0,0 +1,53 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/engine"
+	"github.com/shibukazu/open-ve/go/pkg/logger"
+	"github.com/shibukazu/open-ve/go/pkg/namespace"
+)
+
+// typeURLPrefix is the prefix of the google.protobuf.Any type URLs of the messages of the namespace.
+// The namespace is part of the URL so that the type resolver, which has no request, finds the file descriptor set of the namespace.
+// The validator reads the message name only, after the last slash.
+func typeURLPrefix(name string) string {
+	if name == "" {
+		return "type.googleapis.com/"
+	}
+	return "type.googleapis.com" + namespace.PathPrefix + name + "/"
+}
+
+// engine returns the engine of the namespace selected by the request.
+func (g *Gateway) engine(r *http.Request) (*engine.Engine, error) {
+	return g.namespaces.Engine(r.Context(), namespace.FromRequest(r))
+}
+
+// namespacePathMiddleware serves /namespaces/{name}/... as the rest of the path with the namespace header set,
+// so that the other middlewares and the gateway see the namespace the same way.
+func (g *Gateway) namespacePathMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, namespace.PathPrefix) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		name, path, found := strings.Cut(strings.TrimPrefix(r.URL.Path, namespace.PathPrefix), "/")
+		if !found {
+			http.NotFound(w, r)
+			return
+		}
+		if selected := namespace.FromRequest(r); selected != "" && selected != name {
+			err := failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("namespace %s of the path differs from namespace %s of the %s header", name, selected, namespace.Header))
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			logger.LogError(g.logger, err)
+			return
+		}
+		r.Header.Set(namespace.Header, name)
+		r.URL.Path = "/" + path
+		r.URL.RawPath = ""
+		next.ServeHTTP(w, r)
+	})
+}
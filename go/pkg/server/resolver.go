@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"github.com/shibukazu/open-ve/go/pkg/namespace"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// messageTypeResolver resolves the message types of the registered file descriptor set
+// so that the gateway can decode variables given as messages in google.protobuf.Any.
+// The types linked into the binary take precedence.
+// The namespace of a type URL is given by its prefix, as made by typeURLPrefix.
+type messageTypeResolver struct {
+	namespaces *namespace.Manager
+}
+
+func (r *messageTypeResolver) FindMessageByName(name protoreflect.FullName) (protoreflect.MessageType, error) {
+	return r.findMessage("", name)
+}
+
+func (r *messageTypeResolver) findMessage(namespaceName string, name protoreflect.FullName) (protoreflect.MessageType, error) {
+	if mt, err := protoregistry.GlobalTypes.FindMessageByName(name); err == nil {
+		return mt, nil
+	}
+	// The engine of the namespace is loaded by the request before its variables are decoded.
+	// Namespaces not in use are not resolved, so that type URLs given by clients do not create engines.
+	eng, ok := r.namespaces.LoadedEngine(namespaceName)
+	if !ok {
+		return nil, protoregistry.NotFound
+	}
+	files, err := eng.Files(context.Background())
+	if err != nil || files == nil {
+		return nil, protoregistry.NotFound
+	}
+	desc, err := files.FindDescriptorByName(name)
+	if err != nil {
+		return nil, protoregistry.NotFound
+	}
+	md, ok := desc.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, protoregistry.NotFound
+	}
+	return dynamicpb.NewMessageType(md), nil
+}
+
+func (r *messageTypeResolver) FindMessageByURL(url string) (protoreflect.MessageType, error) {
+	prefix, name := "", url
+	if i := strings.LastIndexByte(url, '/'); i >= 0 {
+		prefix, name = url[:i+1], url[i+1:]
+	}
+	namespaceName := ""
+	if i := strings.Index(prefix, namespace.PathPrefix); i >= 0 {
+		namespaceName = strings.TrimSuffix(prefix[i+len(namespace.PathPrefix):], "/")
+	}
+	return r.findMessage(namespaceName, protoreflect.FullName(name))
+}
+
+func (r *messageTypeResolver) FindExtensionByName(field protoreflect.FullName) (protoreflect.ExtensionType, error) {
+	return protoregistry.GlobalTypes.FindExtensionByName(field)
+}
+
+func (r *messageTypeResolver) FindExtensionByNumber(message protoreflect.FullName, field protoreflect.FieldNumber) (protoreflect.ExtensionType, error) {
+	return protoregistry.GlobalTypes.FindExtensionByNumber(message, field)
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/logger"
+	pbDSL "github.com/shibukazu/open-ve/go/proto/dsl/v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// sseKeepAliveInterval is the interval of the comments sent so that proxies do not close idle event streams.
+const sseKeepAliveInterval = 15 * time.Second
+
+// watchDSLMiddleware serves DSLService.Watch as Server-Sent Events at GET /v1/dsl/watch.
+// Each event has the schema version as its ID and the schema as its data in the same JSON as GET /v1/dsl.
+// Clients resume with the Last-Event-ID header (sent by EventSource on reconnection) or the version query parameter.
+// A failure after the stream started is sent as an error event with the message as its data before the stream ends.
+func (g *Gateway) watchDSLMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/dsl/watch" || r.Method != "GET" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			err := failure.New(appError.ErrServerError, failure.Messagef("streaming is not supported"))
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			logger.LogError(g.logger, err)
+			return
+		}
+
+		version := r.Header.Get("Last-Event-ID")
+		if version == "" {
+			version = r.URL.Query().Get("version")
+		}
+
+		ctx := metadata.NewOutgoingContext(r.Context(), g.outgoingMetadata(r))
+		stream, err := g.dslClient.Watch(ctx, &pbDSL.WatchRequest{Version: version})
+		if err != nil {
+			http.Error(w, err.Error(), runtime.HTTPStatusFromCode(status.Code(err)))
+			logger.LogError(g.logger, err)
+			return
+		}
+
+		// Receive in the background so that keep-alive comments can be sent while waiting for changes
+		resCh := make(chan *pbDSL.WatchResponse)
+		errCh := make(chan error, 1)
+		go func() {
+			for {
+				res, err := stream.Recv()
+				if err != nil {
+					errCh <- err
+					return
+				}
+				select {
+				case resCh <- res:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+
+		marshaler := protojson.MarshalOptions{EmitUnpopulated: true}
+		keepAlive := time.NewTicker(sseKeepAliveInterval)
+		defer keepAlive.Stop()
+		started := false
+		for {
+			var event bytes.Buffer
+			select {
+			case res := <-resCh:
+				data, err := marshaler.Marshal(res.Dsl)
+				if err != nil {
+					logger.LogError(g.logger, failure.Translate(err, appError.ErrServerError, failure.Messagef("failed to marshal dsl")))
+					return
+				}
+				// Compact the JSON so that it fits in a single data line
+				var compacted bytes.Buffer
+				if err := json.Compact(&compacted, data); err != nil {
+					logger.LogError(g.logger, failure.Translate(err, appError.ErrServerError, failure.Messagef("failed to marshal dsl")))
+					return
+				}
+				fmt.Fprintf(&event, "id: %s\nevent: dsl\ndata: %s\n\n", res.Version, compacted.Bytes())
+			case <-keepAlive.C:
+				event.WriteString(": keep-alive\n\n")
+			case err := <-errCh:
+				closed := err == io.EOF || r.Context().Err() != nil
+				if !closed {
+					logger.LogError(g.logger, err)
+				}
+				if !started {
+					// Nothing is sent yet, so the error can still be returned as the status
+					http.Error(w, err.Error(), runtime.HTTPStatusFromCode(status.Code(err)))
+				} else if !closed {
+					// The status is already sent, so the error is sent as an event before the stream ends
+					fmt.Fprintf(w, "event: error\ndata: %s\n\n", strings.ReplaceAll(status.Convert(err).Message(), "\n", " "))
+					flusher.Flush()
+				}
+				return
+			case <-ctx.Done():
+				return
+			}
+
+			if !started {
+				w.Header().Set("Content-Type", "text/event-stream")
+				w.Header().Set("Cache-Control", "no-cache")
+				w.Header().Set("X-Accel-Buffering", "no")
+				w.WriteHeader(http.StatusOK)
+				started = true
+			}
+			if _, err := w.Write(event.Bytes()); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	})
+}
+
+// dslETagMiddleware sets the schema version as the ETag of GET /v1/dsl
+// and responds 304 Not Modified when it matches If-None-Match.
+func (g *Gateway) dslETagMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/dsl" || r.Method != "GET" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &responseRecorder{
+			ResponseWriter: w,
+			body:           &bytes.Buffer{},
+		}
+		next.ServeHTTP(rec, r)
+
+		if rec.statusCode == 0 || rec.statusCode == http.StatusOK {
+			var body struct {
+				Version string `json:"version"`
+			}
+			if err := json.Unmarshal(rec.body.Bytes(), &body); err == nil && body.Version != "" {
+				etag := `"` + body.Version + `"`
+				w.Header().Set("ETag", etag)
+				if etagMatches(r.Header.Get("If-None-Match"), etag) {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+			}
+		}
+		if rec.statusCode != 0 {
+			w.WriteHeader(rec.statusCode)
+		}
+		if _, err := w.Write(rec.body.Bytes()); err != nil {
+			g.logger.Error(failure.Translate(err, appError.ErrServerError, failure.Messagef("failed to write response")).Error())
+		}
+	})
+}
+
+// etagMatches reports whether the If-None-Match header lists the ETag, ignoring the weak validator prefix.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// outgoingMetadata passes the headers the gateway forwards to gRPC, including the authorization header.
+func (g *Gateway) outgoingMetadata(r *http.Request) metadata.MD {
+	md := metadata.MD{}
+	for key, values := range r.Header {
+		if strings.EqualFold(key, "Authorization") {
+			md.Append("authorization", values...)
+			continue
+		}
+		if name, ok := g.incomingHeaderMatcher(key); ok {
+			md.Append(name, values...)
+		}
+	}
+	return md
+}
@@ -0,0 +1,37 @@
+package dslv1
+
+import (
+	"context"
+
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/appError/grpcError"
+	"github.com/shibukazu/open-ve/go/pkg/logger"
+	pb "github.com/shibukazu/open-ve/go/proto/dsl/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func (s *Service) ReadBundle(ctx context.Context, req *pb.ReadBundleRequest) (*pb.ReadBundleResponse, error) {
+	eng, err := s.engine(ctx)
+	if err != nil {
+		logger.LogError(s.logger, err)
+		return nil, grpcError.From(err)
+	}
+	b, err := eng.Bundle(ctx)
+	if err != nil {
+		logger.LogError(s.logger, err)
+		return nil, grpcError.From(err)
+	}
+	encoded, err := proto.MarshalOptions{Deterministic: true}.Marshal(b)
+	if err != nil {
+		err = failure.Translate(err, appError.ErrServerError, failure.Messagef("failed to encode bundle"))
+		logger.LogError(s.logger, err)
+		return nil, grpcError.From(err)
+	}
+	res := &pb.ReadBundleResponse{Bundle: encoded, Version: b.Version}
+	if s.signer != nil {
+		res.Signature = s.signer.Sign(encoded)
+		res.KeyId = s.signer.KeyID()
+	}
+	return res, nil
+}
@@ -0,0 +1,56 @@
+package dslv1
+
+import (
+	"context"
+
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/appError/grpcError"
+	dslPkg "github.com/shibukazu/open-ve/go/pkg/dsl"
+	"github.com/shibukazu/open-ve/go/pkg/logger"
+	pb "github.com/shibukazu/open-ve/go/proto/dsl/v1"
+)
+
+func (s *Service) RegisterDataset(ctx context.Context, req *pb.RegisterDatasetRequest) (*pb.RegisterDatasetResponse, error) {
+	if req.Name == "" {
+		err := failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("name is required"))
+		logger.LogError(s.logger, err)
+		return nil, grpcError.From(err)
+	}
+	dataset := &dslPkg.Dataset{
+		Name:    req.Name,
+		Values:  req.Values,
+		Entries: req.Entries,
+	}
+	eng, err := s.engine(ctx)
+	if err != nil {
+		logger.LogError(s.logger, err)
+		return nil, grpcError.From(err)
+	}
+	if err := eng.LoadDataset(ctx, dataset); err != nil {
+		logger.LogError(s.logger, err)
+		return nil, grpcError.From(err)
+	}
+	return &pb.RegisterDatasetResponse{}, nil
+}
+
+func (s *Service) ReadDataset(ctx context.Context, req *pb.ReadDatasetRequest) (*pb.ReadDatasetResponse, error) {
+	eng, err := s.engine(ctx)
+	if err != nil {
+		logger.LogError(s.logger, err)
+		return nil, grpcError.From(err)
+	}
+	dataset, err := eng.Dataset(ctx, req.Name)
+	if err != nil {
+		logger.LogError(s.logger, err)
+		return nil, grpcError.From(err)
+	}
+	return &pb.ReadDatasetResponse{
+		Dataset: &pb.Dataset{
+			Name:    dataset.Name,
+			Kind:    dataset.Kind,
+			Values:  dataset.Values,
+			Entries: dataset.Entries,
+		},
+	}, nil
+}
@@ -3,23 +3,37 @@ package dslv1
 import (
 	"context"
 
+	"github.com/morikuni/failure/v2"
 	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/appError/grpcError"
 	dslPkg "github.com/shibukazu/open-ve/go/pkg/dsl"
 	"github.com/shibukazu/open-ve/go/pkg/logger"
 	pb "github.com/shibukazu/open-ve/go/proto/dsl/v1"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 func (s *Service) Read(ctx context.Context, req *pb.ReadRequest) (*pb.ReadResponse, error) {
-	dsl, err := s.dslReader.Read(ctx)
+	eng, err := s.engine(ctx)
 	if err != nil {
 		logger.LogError(s.logger, err)
-		return nil, appError.ToGRPCError(err)
+		return nil, grpcError.From(err)
 	}
-	return toProto(dsl), nil
+	dsl, err := eng.Schema(ctx)
+	if err != nil {
+		logger.LogError(s.logger, err)
+		return nil, grpcError.From(err)
+	}
+	res, err := ToProto(dsl)
+	if err != nil {
+		logger.LogError(s.logger, err)
+		return nil, grpcError.From(err)
+	}
+	return res, nil
 }
 
-func toProto(dsl *dslPkg.DSL) *pb.ReadResponse {
-	res := &pb.ReadResponse{}
+// ToProto converts the DSL with the version it was read with.
+func ToProto(dsl *dslPkg.DSL) (*pb.ReadResponse, error) {
+	res := &pb.ReadResponse{FileDescriptorSet: dsl.FileDescriptorSet}
 	res.Validations = make([]*pb.Validation, len(dsl.Validations))
 	for i, validation := range dsl.Validations {
 		res.Validations[i] = &pb.Validation{
@@ -27,12 +41,42 @@ func toProto(dsl *dslPkg.DSL) *pb.ReadResponse {
 			Cels:      validation.Cels,
 			Variables: make([]*pb.Variable, len(validation.Variables)),
 		}
+		for _, computed := range validation.Computed {
+			res.Validations[i].Computed = append(res.Validations[i].Computed, &pb.ComputedVariable{
+				Name: computed.Name,
+				Cel:  computed.Cel,
+			})
+		}
+		for _, profile := range validation.Profiles {
+			res.Validations[i].Profiles = append(res.Validations[i].Profiles, &pb.Profile{
+				Name:     profile.Name,
+				Cels:     profile.Cels,
+				Exclude:  profile.Exclude,
+				Optional: profile.Optional,
+			})
+		}
+		for _, testCase := range validation.TestCases {
+			tc := &pb.TestCase{Name: testCase.Name, Profile: testCase.Profile, Expected: testCase.Expected}
+			for _, variable := range testCase.Variables {
+				value, err := structpb.NewValue(variable.Value)
+				if err != nil {
+					return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("invalid value of %s in test case %s", variable.Name, testCase.Name))
+				}
+				tc.Variables = append(tc.Variables, &pb.TestVariable{Name: variable.Name, Value: value})
+			}
+			res.Validations[i].TestCases = append(res.Validations[i].TestCases, tc)
+		}
 		for j, variable := range validation.Variables {
 			res.Validations[i].Variables[j] = &pb.Variable{
 				Name: variable.Name,
 				Type: variable.Type,
+				Path: variable.Path,
 			}
 		}
 	}
-	return res
+	for _, dataset := range dsl.Datasets {
+		res.Datasets = append(res.Datasets, &pb.Dataset{Name: dataset.Name, Kind: dataset.Kind})
+	}
+	res.Version = dsl.Version
+	return res, nil
 }
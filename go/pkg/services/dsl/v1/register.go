@@ -5,37 +5,55 @@ import (
 
 	"github.com/morikuni/failure/v2"
 	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/appError/grpcError"
 	dslPkg "github.com/shibukazu/open-ve/go/pkg/dsl"
 	"github.com/shibukazu/open-ve/go/pkg/logger"
+	"github.com/shibukazu/open-ve/go/pkg/namespace"
 	pb "github.com/shibukazu/open-ve/go/proto/dsl/v1"
 )
 
 func (s *Service) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.RegisterResponse, error) {
-	dsl, err := toDSL(req)
+	dsl, err := ToDSL(req)
 	if err != nil {
 		logger.LogError(s.logger, err)
-		return nil, appError.ToGRPCError(err)
+		return nil, grpcError.From(err)
 	}
-	if err := s.dslReader.Register(ctx, dsl); err != nil {
+	eng, err := s.engine(ctx)
+	if err != nil {
+		logger.LogError(s.logger, err)
+		return nil, grpcError.From(err)
+	}
+	if err := eng.Load(ctx, dsl); err != nil {
 		logger.LogError(s.logger, err)
-		return nil, appError.ToGRPCError(err)
+		return nil, grpcError.From(err)
 	}
-	if s.mode == "slave" {
+	// The master forwards the requests of the default namespace only, so the slave reports its schema only.
+	if s.mode == "slave" && namespace.FromContext(ctx) == "" {
 		err := s.slaveRegistrar.Register(ctx)
 		if err != nil {
 			logger.LogError(s.logger, err)
-			return nil, appError.ToGRPCError(err)
+			return nil, grpcError.From(err)
 		}
 	}
 
 	return &pb.RegisterResponse{}, nil
 }
 
-func toDSL(req *pb.RegisterRequest) (*dslPkg.DSL, error) {
+// ToDSL converts a registration to the DSL, checking the required fields.
+func ToDSL(req *pb.RegisterRequest) (*dslPkg.DSL, error) {
 	dsl := &dslPkg.DSL{}
 	if req.Validations == nil {
 		return nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("validations is required"))
 	}
+	dsl.FileDescriptorSet = req.FileDescriptorSet
+	for _, dataset := range req.Datasets {
+		dsl.Datasets = append(dsl.Datasets, dslPkg.Dataset{
+			Name:    dataset.Name,
+			Kind:    dataset.Kind,
+			Values:  dataset.Values,
+			Entries: dataset.Entries,
+		})
+	}
 	dsl.Validations = make([]dslPkg.Validation, len(req.Validations))
 	for i, validation := range req.Validations {
 		if validation.Id == "" {
@@ -52,6 +70,27 @@ func toDSL(req *pb.RegisterRequest) (*dslPkg.DSL, error) {
 			Cels:      validation.Cels,
 			Variables: make([]dslPkg.Variable, len(validation.Variables)),
 		}
+		for _, computed := range validation.Computed {
+			dsl.Validations[i].Computed = append(dsl.Validations[i].Computed, dslPkg.ComputedVariable{
+				Name: computed.Name,
+				Cel:  computed.Cel,
+			})
+		}
+		for _, profile := range validation.Profiles {
+			dsl.Validations[i].Profiles = append(dsl.Validations[i].Profiles, dslPkg.Profile{
+				Name:     profile.Name,
+				Cels:     profile.Cels,
+				Exclude:  profile.Exclude,
+				Optional: profile.Optional,
+			})
+		}
+		for _, tc := range validation.TestCases {
+			testCase := dslPkg.TestCase{Name: tc.Name, Profile: tc.Profile, Expected: tc.Expected}
+			for _, variable := range tc.Variables {
+				testCase.Variables = append(testCase.Variables, dslPkg.TestVeriable{Name: variable.Name, Value: variable.Value.AsInterface()})
+			}
+			dsl.Validations[i].TestCases = append(dsl.Validations[i].TestCases, testCase)
+		}
 		for j, variable := range validation.Variables {
 			if variable.Name == "" {
 				return nil, failure.New(appError.ErrDSLSyntaxError, failure.Messagef("variable name is required"))
@@ -62,6 +101,7 @@ func toDSL(req *pb.RegisterRequest) (*dslPkg.DSL, error) {
 			dsl.Validations[i].Variables[j] = dslPkg.Variable{
 				Name: variable.Name,
 				Type: variable.Type,
+				Path: variable.Path,
 			}
 		}
 	}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"log/slog"
 
-	"github.com/shibukazu/open-ve/go/pkg/dsl/reader"
+	"github.com/shibukazu/open-ve/go/pkg/dsl/bundle"
+	"github.com/shibukazu/open-ve/go/pkg/engine"
+	"github.com/shibukazu/open-ve/go/pkg/namespace"
 	"github.com/shibukazu/open-ve/go/pkg/slave"
 	pb "github.com/shibukazu/open-ve/go/proto/dsl/v1"
 )
@@ -13,10 +15,19 @@ type Service struct {
 	pb.UnimplementedDSLServiceServer
 	logger         *slog.Logger
 	mode           string
-	dslReader      *reader.DSLReader
+	namespaces     *namespace.Manager
 	slaveRegistrar *slave.SlaveRegistrar
+	// signer signs the bundles. Bundles are not signed if nil.
+	signer *bundle.Signer
+	// shutdown is closed when the server stops, which ends the watch streams.
+	shutdown <-chan struct{}
 }
 
-func NewService(ctx context.Context, logger *slog.Logger, mode string, dslReader *reader.DSLReader, slaveRegistrar *slave.SlaveRegistrar) *Service {
-	return &Service{logger: logger, mode: mode, dslReader: dslReader, slaveRegistrar: slaveRegistrar}
+func NewService(ctx context.Context, logger *slog.Logger, mode string, namespaces *namespace.Manager, slaveRegistrar *slave.SlaveRegistrar, signer *bundle.Signer) *Service {
+	return &Service{logger: logger, mode: mode, namespaces: namespaces, slaveRegistrar: slaveRegistrar, signer: signer, shutdown: ctx.Done()}
+}
+
+// engine returns the engine of the namespace selected by the request.
+func (s *Service) engine(ctx context.Context) (*engine.Engine, error) {
+	return s.namespaces.Engine(ctx, namespace.FromContext(ctx))
 }
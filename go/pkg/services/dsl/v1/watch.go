@@ -0,0 +1,55 @@
+package dslv1
+
+import (
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/appError/grpcError"
+	"github.com/shibukazu/open-ve/go/pkg/logger"
+	pb "github.com/shibukazu/open-ve/go/proto/dsl/v1"
+)
+
+// Watch sends the current schema unless the client already has its version,
+// then sends the schema each time a DSL or a dataset changing the version is registered.
+// While no DSL is registered, the stream waits for the first registration.
+// Other failures to read the schema end the stream with their status, and the client resumes with its version.
+func (s *Service) Watch(req *pb.WatchRequest, stream pb.DSLService_WatchServer) error {
+	ctx := stream.Context()
+	eng, err := s.engine(ctx)
+	if err != nil {
+		logger.LogError(s.logger, err)
+		return grpcError.From(err)
+	}
+	version := req.Version
+	for {
+		changed := eng.Changed()
+
+		dsl, err := eng.Schema(ctx)
+		switch {
+		case failure.Is(err, appError.ErrNotFound):
+			// No DSL is registered yet, so the next registration is awaited
+		case err != nil:
+			logger.LogError(s.logger, err)
+			return grpcError.From(err)
+		default:
+			res, err := ToProto(dsl)
+			if err != nil {
+				logger.LogError(s.logger, err)
+				return grpcError.From(err)
+			}
+			if res.Version != version {
+				if err := stream.Send(&pb.WatchResponse{Version: res.Version, Dsl: res}); err != nil {
+					return err
+				}
+				version = res.Version
+			}
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil
+		case <-s.shutdown:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package namespacev1
+
+import (
+	"context"
+
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/appError/grpcError"
+	"github.com/shibukazu/open-ve/go/pkg/logger"
+	"github.com/shibukazu/open-ve/go/pkg/namespace"
+	storePkg "github.com/shibukazu/open-ve/go/pkg/store"
+	pb "github.com/shibukazu/open-ve/go/proto/namespace/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Service) Create(ctx context.Context, req *pb.CreateRequest) (*pb.CreateResponse, error) {
+	if err := checkNoNamespace(ctx); err != nil {
+		logger.LogError(s.logger, err)
+		return nil, grpcError.From(err)
+	}
+	created, err := s.namespaces.Create(ctx, req.Name, req.PresharedKey, req.PrivilegedKey)
+	if err != nil {
+		logger.LogError(s.logger, err)
+		return nil, grpcError.From(err)
+	}
+	return &pb.CreateResponse{Namespace: toProto(created)}, nil
+}
+
+func (s *Service) List(ctx context.Context, req *pb.ListRequest) (*pb.ListResponse, error) {
+	if err := checkNoNamespace(ctx); err != nil {
+		logger.LogError(s.logger, err)
+		return nil, grpcError.From(err)
+	}
+	namespaces, err := s.namespaces.List(ctx)
+	if err != nil {
+		logger.LogError(s.logger, err)
+		return nil, grpcError.From(err)
+	}
+	res := &pb.ListResponse{Namespaces: make([]*pb.Namespace, len(namespaces))}
+	for i, n := range namespaces {
+		res.Namespaces[i] = toProto(n)
+	}
+	return res, nil
+}
+
+func (s *Service) Delete(ctx context.Context, req *pb.DeleteRequest) (*pb.DeleteResponse, error) {
+	if err := checkNoNamespace(ctx); err != nil {
+		logger.LogError(s.logger, err)
+		return nil, grpcError.From(err)
+	}
+	if err := s.namespaces.Delete(ctx, req.Name); err != nil {
+		logger.LogError(s.logger, err)
+		return nil, grpcError.From(err)
+	}
+	return &pb.DeleteResponse{}, nil
+}
+
+// checkNoNamespace refuses the requests selecting a namespace, which may be authenticated with the keys of the namespace.
+func checkNoNamespace(ctx context.Context) error {
+	if name := namespace.FromContext(ctx); name != "" {
+		return failure.New(appError.ErrPermissionDenied, failure.Messagef("namespaces can't be managed within namespace %s", name))
+	}
+	return nil
+}
+
+func toProto(n *storePkg.Namespace) *pb.Namespace {
+	return &pb.Namespace{
+		Name:             n.Name,
+		HasPresharedKey:  len(n.PresharedKeyHash) > 0,
+		HasPrivilegedKey: len(n.PrivilegedKeyHash) > 0,
+		CreateTime:       timestamppb.New(n.CreatedAt),
+	}
+}
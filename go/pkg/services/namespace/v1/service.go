@@ -0,0 +1,19 @@
+package namespacev1
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/shibukazu/open-ve/go/pkg/namespace"
+	pb "github.com/shibukazu/open-ve/go/proto/namespace/v1"
+)
+
+type Service struct {
+	pb.UnimplementedNamespaceServiceServer
+	logger     *slog.Logger
+	namespaces *namespace.Manager
+}
+
+func NewService(ctx context.Context, logger *slog.Logger, namespaces *namespace.Manager) *Service {
+	return &Service{logger: logger, namespaces: namespaces}
+}
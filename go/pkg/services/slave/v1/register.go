@@ -3,15 +3,27 @@ package slavev1
 import (
 	"context"
 
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/appError/grpcError"
 	"github.com/shibukazu/open-ve/go/pkg/config"
+	"github.com/shibukazu/open-ve/go/pkg/logger"
+	"github.com/shibukazu/open-ve/go/pkg/namespace"
 	pb "github.com/shibukazu/open-ve/go/proto/slave/v1"
 )
 
 func (s *Service) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.RegisterResponse, error) {
+	// Slaves serve the default namespace, and must not be registered with the keys of another namespace
+	if name := namespace.FromContext(ctx); name != "" {
+		err := failure.New(appError.ErrPermissionDenied, failure.Messagef("slaves can't be registered within namespace %s", name))
+		logger.LogError(s.logger, err)
+		return nil, grpcError.From(err)
+	}
 	authnConfig := config.AuthnConfig{
 		Method: req.Authn.GetMethod(),
 		Preshared: config.PresharedConfig{
-			Key: req.Authn.Preshared.GetKey(),
+			Key:           req.Authn.Preshared.GetKey(),
+			PrivilegedKey: req.Authn.Preshared.GetPrivilegedKey(),
 		},
 	}
 
@@ -0,0 +1,55 @@
+package validatev1
+
+import (
+	"context"
+
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/appError/grpcError"
+	"github.com/shibukazu/open-ve/go/pkg/logger"
+	pb "github.com/shibukazu/open-ve/go/proto/validate/v1"
+)
+
+func (s *Service) BulkCheck(ctx context.Context, req *pb.BulkCheckRequest) (*pb.BulkCheckResponse, error) {
+	if req.Id == "" {
+		err := failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("id is required"))
+		logger.LogError(s.logger, err)
+		return nil, grpcError.From(err)
+	}
+
+	// Records whose variables cannot be converted are reported without being evaluated
+	records := make([]map[string]interface{}, 0, len(req.Records))
+	recordIndexes := make([]int, 0, len(req.Records))
+	results := make([]*pb.RecordResult, len(req.Records))
+	for idx, record := range req.Records {
+		variables, err := convertAnyMapToInterfaceMap(record.Variables)
+		if err != nil {
+			results[idx] = &pb.RecordResult{Index: int32(idx), IsValid: false, Error: err.Error()}
+			continue
+		}
+		records = append(records, variables)
+		recordIndexes = append(recordIndexes, idx)
+	}
+
+	eng, err := s.engine(ctx)
+	if err != nil {
+		logger.LogError(s.logger, err)
+		return nil, grpcError.From(err)
+	}
+	recordResults, err := eng.CheckBulk(s.withRequestContext(ctx), req.Id, records)
+	if err != nil {
+		logger.LogError(s.logger, err)
+		return nil, grpcError.From(err)
+	}
+	for i, recordResult := range recordResults {
+		idx := recordIndexes[i]
+		result := &pb.RecordResult{Index: int32(idx), IsValid: recordResult.IsValid, Message: recordResult.Message, Errors: toProtoErrors(recordResult.Errors)}
+		if recordResult.Err != nil {
+			result.IsValid = false
+			result.Error = recordResult.Err.Error()
+		}
+		results[idx] = result
+	}
+
+	return &pb.BulkCheckResponse{Results: results}, nil
+}
@@ -5,6 +5,9 @@ import (
 
 	"github.com/morikuni/failure/v2"
 	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/appError/grpcError"
+	"github.com/shibukazu/open-ve/go/pkg/authn"
+	"github.com/shibukazu/open-ve/go/pkg/engine"
 	"github.com/shibukazu/open-ve/go/pkg/logger"
 	pb "github.com/shibukazu/open-ve/go/proto/validate/v1"
 	"google.golang.org/protobuf/types/known/anypb"
@@ -12,25 +15,87 @@ import (
 )
 
 func (s *Service) Check(ctx context.Context, req *pb.CheckRequest) (*pb.CheckResponse, error) {
+	if req.Explain {
+		if err := s.authenticator.Authorize(ctx, authn.PermissionExplain); err != nil {
+			logger.LogError(s.logger, err)
+			return nil, grpcError.From(err)
+		}
+	}
+
 	results := make([]*pb.ValidationResult, 0, len(req.Validations))
+	ctx = s.withRequestContext(ctx)
 
 	for _, validation := range req.Validations {
-		variables, err := convertAnyMapToInterfaceMap(validation.Variables)
-		if err != nil {
-			logger.LogError(s.logger, err)
-			return nil, appError.ToGRPCError(err)
-		}
-		is_valid, msg, err := s.validator.Validate(validation.Id, variables)
+		result, err := s.check(ctx, validation, req.Explain)
 		if err != nil {
 			logger.LogError(s.logger, err)
-			return nil, appError.ToGRPCError(err)
+			return nil, grpcError.From(err)
 		}
-		results = append(results, &pb.ValidationResult{Id: validation.Id, IsValid: is_valid, Message: msg})
+		results = append(results, result)
 	}
 
 	return &pb.CheckResponse{Results: results}, nil
 }
 
+func (s *Service) check(ctx context.Context, validation *pb.Validation, explain bool) (*pb.ValidationResult, error) {
+	variables, err := convertAnyMapToInterfaceMap(validation.Variables)
+	if err != nil {
+		return nil, err
+	}
+	opts := []engine.CheckOption{engine.WithProfile(validation.Profile)}
+	if explain {
+		opts = append(opts, engine.WithExplain())
+	}
+	eng, err := s.engine(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := eng.Check(ctx, validation.Id, variables, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ValidationResult{
+		Id:           validation.Id,
+		IsValid:      result.IsValid,
+		Message:      result.Message,
+		Explanations: toProtoExplanations(result.Explanations),
+		Errors:       toProtoErrors(result.Errors),
+		Computed:     result.Computed,
+		Profile:      validation.Profile,
+	}, nil
+}
+
+func toProtoErrors(ruleErrors []engine.RuleError) []*pb.ValidationError {
+	ret := make([]*pb.ValidationError, len(ruleErrors))
+	for i, ruleError := range ruleErrors {
+		ret[i] = &pb.ValidationError{
+			Cel:        ruleError.Cel,
+			Message:    ruleError.Message,
+			Attributes: ruleError.Attributes,
+		}
+	}
+	return ret
+}
+
+func toProtoExplanations(explanations []engine.Explanation) []*pb.Explanation {
+	ret := make([]*pb.Explanation, len(explanations))
+	for i, explanation := range explanations {
+		ret[i] = &pb.Explanation{
+			Cel:         explanation.Cel,
+			IsValid:     explanation.IsValid,
+			Variables:   explanation.Variables,
+			Expressions: make([]*pb.EvaluatedExpression, len(explanation.Expressions)),
+		}
+		for j, expression := range explanation.Expressions {
+			ret[i].Expressions[j] = &pb.EvaluatedExpression{
+				Expression: expression.Expression,
+				Value:      expression.Value,
+			}
+		}
+	}
+	return ret
+}
+
 func convertAnyMapToInterfaceMap(anyMap map[string]*anypb.Any) (map[string]interface{}, error) {
 	interfaceMap := make(map[string]interface{})
 	for key, anyValue := range anyMap {
@@ -116,9 +181,10 @@ func convertAnyMapToInterfaceMap(anyMap map[string]*anypb.Any) (map[string]inter
 			if err := anyValue.UnmarshalTo(bytesValue); err != nil {
 				return nil, failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("failed to unmarshal bytes value"))
 			}
-			val = bytesValue
+			val = bytesValue.Value
 		default:
-			return nil, failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("unsupported type: %s", anyValue.TypeUrl))
+			// Messages of the registered file descriptor set are unpacked by the validator
+			val = anyValue
 		}
 
 		interfaceMap[key] = val
@@ -0,0 +1,74 @@
+package validatev1
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/appError/grpcError"
+	"github.com/shibukazu/open-ve/go/pkg/logger"
+	pb "github.com/shibukazu/open-ve/go/proto/validate/v1"
+)
+
+func (s *Service) CheckDocument(ctx context.Context, req *pb.CheckDocumentRequest) (*pb.CheckDocumentResponse, error) {
+	if req.Id == "" {
+		err := failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("id is required"))
+		logger.LogError(s.logger, err)
+		return nil, grpcError.From(err)
+	}
+	document, err := decodeDocument(req)
+	if err != nil {
+		logger.LogError(s.logger, err)
+		return nil, grpcError.From(err)
+	}
+
+	eng, err := s.engine(ctx)
+	if err != nil {
+		logger.LogError(s.logger, err)
+		return nil, grpcError.From(err)
+	}
+	result, err := eng.CheckDocument(s.withRequestContext(ctx), req.Id, document)
+	if err != nil {
+		logger.LogError(s.logger, err)
+		return nil, grpcError.From(err)
+	}
+
+	res := &pb.CheckDocumentResponse{
+		Id:       req.Id,
+		IsValid:  result.IsValid,
+		Message:  result.Message,
+		Failures: make([]*pb.DocumentFailure, len(result.Failures)),
+	}
+	for i, f := range result.Failures {
+		res.Failures[i] = &pb.DocumentFailure{
+			Cel:     f.Cel,
+			Paths:   f.Paths,
+			Message: f.Message,
+		}
+	}
+	return res, nil
+}
+
+// decodeDocument returns the document of the request. The numbers of document_json are decoded as json.Number,
+// so that integers are converted to the variable types without being rounded through float64.
+func decodeDocument(req *pb.CheckDocumentRequest) (interface{}, error) {
+	if req.DocumentJson == "" {
+		if req.Document == nil {
+			return nil, failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("document is required"))
+		}
+		return req.Document.AsInterface(), nil
+	}
+	var document interface{}
+	decoder := json.NewDecoder(strings.NewReader(req.DocumentJson))
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil {
+		return nil, failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("failed to decode document"))
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("document must be a single JSON value"))
+	}
+	return document, nil
+}
@@ -3,17 +3,50 @@ package validatev1
 import (
 	"context"
 	"log/slog"
+	"strings"
+	"time"
 
-	"github.com/shibukazu/open-ve/go/pkg/validator"
+	"github.com/shibukazu/open-ve/go/pkg/authn"
+	"github.com/shibukazu/open-ve/go/pkg/engine"
+	"github.com/shibukazu/open-ve/go/pkg/namespace"
 	pb "github.com/shibukazu/open-ve/go/proto/validate/v1"
+	"google.golang.org/grpc/metadata"
 )
 
 type Service struct {
 	pb.UnimplementedValidateServiceServer
-	logger    *slog.Logger
-	validator *validator.Validator
+	logger            *slog.Logger
+	namespaces        *namespace.Manager
+	authenticator     authn.Authenticator
+	streamConcurrency int
+	contextMetadata   []string
 }
 
-func NewService(ctx context.Context, logger *slog.Logger, validator *validator.Validator) *Service {
-	return &Service{logger: logger, validator: validator}
+func NewService(ctx context.Context, logger *slog.Logger, namespaces *namespace.Manager, authenticator authn.Authenticator, streamConcurrency int, contextMetadata []string) *Service {
+	if streamConcurrency < 1 {
+		streamConcurrency = 1
+	}
+	return &Service{logger: logger, namespaces: namespaces, authenticator: authenticator, streamConcurrency: streamConcurrency, contextMetadata: contextMetadata}
+}
+
+// engine returns the engine of the namespace selected by the request.
+func (s *Service) engine(ctx context.Context) (*engine.Engine, error) {
+	return s.namespaces.Engine(ctx, namespace.FromContext(ctx))
+}
+
+// withRequestContext attaches the principal and the selected metadata of the request for the rules.
+func (s *Service) withRequestContext(ctx context.Context) context.Context {
+	requestContext := &engine.RequestContext{
+		Principal: authn.PrincipalFromContext(ctx),
+		Metadata:  make(map[string]string, len(s.contextMetadata)),
+		Time:      time.Now(),
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, key := range s.contextMetadata {
+		key = strings.ToLower(key)
+		if values := md.Get(key); len(values) != 0 {
+			requestContext.Metadata[key] = strings.Join(values, ",")
+		}
+	}
+	return engine.WithRequestContext(ctx, requestContext)
 }
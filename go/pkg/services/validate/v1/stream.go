@@ -0,0 +1,98 @@
+package validatev1
+
+import (
+	"io"
+	"sync"
+
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/authn"
+	"github.com/shibukazu/open-ve/go/pkg/logger"
+	pb "github.com/shibukazu/open-ve/go/proto/validate/v1"
+)
+
+// CheckStream evaluates requests concurrently up to the configured concurrency.
+// Responses may be sent in a different order than requests, so clients match them by correlation ID.
+// When the client does not read responses, the workers block on sending and the stream
+// stops receiving requests, which applies HTTP/2 flow control back to the client.
+func (s *Service) CheckStream(stream pb.ValidateService_CheckStreamServer) error {
+	ctx := stream.Context()
+	sem := make(chan struct{}, s.streamConcurrency)
+	resCh := make(chan *pb.CheckStreamResponse, s.streamConcurrency)
+	sendErrCh := make(chan error, 1)
+
+	go func() {
+		var sendErr error
+		for res := range resCh {
+			// keep draining so that the workers never block after a send failure
+			if sendErr != nil {
+				continue
+			}
+			sendErr = stream.Send(res)
+		}
+		sendErrCh <- sendErr
+	}()
+
+	wg := &sync.WaitGroup{}
+	var recvErr error
+	for recvErr == nil {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			recvErr = err
+			break
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			recvErr = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func(req *pb.CheckStreamRequest) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			resCh <- s.checkStreamRequest(req, stream)
+		}(req)
+	}
+
+	wg.Wait()
+	close(resCh)
+	sendErr := <-sendErrCh
+
+	if recvErr != nil {
+		return recvErr
+	}
+	if sendErr != nil {
+		return sendErr
+	}
+	return nil
+}
+
+func (s *Service) checkStreamRequest(req *pb.CheckStreamRequest, stream pb.ValidateService_CheckStreamServer) *pb.CheckStreamResponse {
+	res := &pb.CheckStreamResponse{CorrelationId: req.CorrelationId}
+	if req.Validation == nil {
+		err := failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("validation is required"))
+		logger.LogError(s.logger, err)
+		res.Error = err.Error()
+		return res
+	}
+	if req.Explain {
+		if err := s.authenticator.Authorize(stream.Context(), authn.PermissionExplain); err != nil {
+			logger.LogError(s.logger, err)
+			res.Error = err.Error()
+			return res
+		}
+	}
+	result, err := s.check(s.withRequestContext(stream.Context()), req.Validation, req.Explain)
+	if err != nil {
+		logger.LogError(s.logger, err)
+		res.Error = err.Error()
+		return res
+	}
+	res.Result = result
+	return res
+}
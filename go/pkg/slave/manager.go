@@ -1,11 +1,11 @@
 package slave
 
 import (
-	"fmt"
 	"log/slog"
 	"sync"
 
 	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
 	"github.com/shibukazu/open-ve/go/pkg/config"
 )
 
@@ -43,5 +43,5 @@ func (m *SlaveManager) FindSlave(validationId string) (*Slave, error) {
 			}
 		}
 	}
-	return nil, failure.New(fmt.Sprintf("slave node that can handle validation id (%s) is not found", validationId))
+	return nil, failure.New(appError.ErrNotFound, failure.Messagef("slave node that can handle validation id (%s) is not found", validationId))
 }
@@ -14,7 +14,7 @@ import (
 	"github.com/morikuni/failure/v2"
 	"github.com/shibukazu/open-ve/go/pkg/appError"
 	"github.com/shibukazu/open-ve/go/pkg/config"
-	"github.com/shibukazu/open-ve/go/pkg/dsl/reader"
+	"github.com/shibukazu/open-ve/go/pkg/engine"
 	"github.com/shibukazu/open-ve/go/pkg/logger"
 )
 
@@ -25,12 +25,12 @@ type SlaveRegistrar struct {
 	SlaveAuthn        config.AuthnConfig
 	MasterHTTPAddress string
 	MasterAuthn       config.AuthnConfig
-	dslReader         *reader.DSLReader
+	engine            *engine.Engine
 	httpClient        *http.Client
 	logger            *slog.Logger
 }
 
-func NewSlaveRegistrar(id, slaveHTTPAddress string, slaveTLSEnabled bool, slaveAuthn config.AuthnConfig, masterHTTPAddress string, masterAuthn config.AuthnConfig, dslReader *reader.DSLReader, logger *slog.Logger) *SlaveRegistrar {
+func NewSlaveRegistrar(id, slaveHTTPAddress string, slaveTLSEnabled bool, slaveAuthn config.AuthnConfig, masterHTTPAddress string, masterAuthn config.AuthnConfig, engine *engine.Engine, logger *slog.Logger) *SlaveRegistrar {
 	var client *http.Client
 	masterTLSEnabled := strings.HasPrefix(masterHTTPAddress, "https")
 	if masterTLSEnabled {
@@ -50,7 +50,7 @@ func NewSlaveRegistrar(id, slaveHTTPAddress string, slaveTLSEnabled bool, slaveA
 		SlaveAuthn:        slaveAuthn,
 		MasterHTTPAddress: masterHTTPAddress,
 		MasterAuthn:       masterAuthn,
-		dslReader:         dslReader,
+		engine:            engine,
 		httpClient:        client,
 		logger:            logger,
 	}
@@ -80,7 +80,7 @@ func (s *SlaveRegistrar) RegisterTimer(ctx context.Context, wg *sync.WaitGroup)
 }
 
 func (s *SlaveRegistrar) Register(ctx context.Context) error {
-	dsl, err := s.dslReader.Read(ctx)
+	dsl, err := s.engine.Schema(ctx)
 	if err != nil {
 		return err
 	}
@@ -96,7 +96,8 @@ func (s *SlaveRegistrar) Register(ctx context.Context) error {
 		"authn": map[string]interface{}{
 			"method": s.SlaveAuthn.Method,
 			"preshared": map[string]interface{}{
-				"key": s.SlaveAuthn.Preshared.Key,
+				"key":            s.SlaveAuthn.Preshared.Key,
+				"privileged_key": s.SlaveAuthn.Preshared.PrivilegedKey,
 			},
 		},
 	}
@@ -0,0 +1,536 @@
+//go:build !js
+
+package store
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/dsl"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// boltFormatVersion is the version of the layout of the file, kept in the meta bucket.
+	// Version 1 kept the schema and each part of a validation as JSON.
+	boltFormatVersion    = "2"
+	boltLockTimeout      = 5 * time.Second
+	boltFileMode         = os.FileMode(0600)
+	boltDirectoryMode    = os.FileMode(0700)
+	boltValidationPrefix = "validation:"
+)
+
+var (
+	boltMetaBucket       = []byte("meta")
+	boltFormatVersionKey = []byte("formatVersion")
+	boltDatasetsBucket   = []byte("datasets")
+	boltSchemaKey        = []byte("schema")
+	boltDescriptorSetKey = []byte("descriptors")
+	boltVersionKey       = []byte("version")
+)
+
+// BoltStore keeps the DSL in a bbolt file on local disk so that a single node survives restarts without Redis.
+// Each node has its own bucket, and each write is a transaction synced to disk on commit.
+// Only one process can open the file at a time.
+type BoltStore struct {
+	id string
+	db *bolt.DB
+	// subscribers are within the process, as no other process can open the file.
+	subscribers *subscribers
+	// namespaces are the stores of the namespaces, kept so that each has the same subscribers.
+	namespaces map[string]*BoltStore
+	mu         sync.Mutex
+}
+
+func NewBoltStore(id string, path string) (*BoltStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), boltDirectoryMode); err != nil {
+		return nil, failure.Translate(err, appError.ErrConfigError, failure.Messagef("failed to create the directory of %s", path))
+	}
+	db, err := bolt.Open(path, boltFileMode, &bolt.Options{Timeout: boltLockTimeout})
+	if err != nil {
+		return nil, failure.Translate(err, appError.ErrConfigError, failure.Messagef("failed to open %s", path))
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		meta, err := tx.CreateBucketIfNotExists(boltMetaBucket)
+		if err != nil {
+			return err
+		}
+		version := meta.Get(boltFormatVersionKey)
+		switch string(version) {
+		case boltFormatVersion:
+			return nil
+		case "":
+		case "1":
+			if err := migrateBoltV1(tx); err != nil {
+				return err
+			}
+		default:
+			return failure.New(appError.ErrConfigError, failure.Messagef("unsupported format version %s of %s", version, path))
+		}
+		return meta.Put(boltFormatVersionKey, []byte(boltFormatVersion))
+	})
+	if err != nil {
+		db.Close()
+		return nil, failure.Translate(err, appError.ErrConfigError, failure.Messagef("failed to initialize %s", path))
+	}
+	return &BoltStore{id: id, db: db, subscribers: newSubscribers(), namespaces: make(map[string]*BoltStore)}, nil
+}
+
+// migrateBoltV1 rewrites the DSL of every node from the JSON of version 1.
+func migrateBoltV1(tx *bolt.Tx) error {
+	return tx.ForEach(func(name []byte, bucket *bolt.Bucket) error {
+		if bytes.Equal(name, boltMetaBucket) {
+			return nil
+		}
+		schemaJSON := bucket.Get(boltSchemaKey)
+		if schemaJSON == nil {
+			return nil
+		}
+		schema, err := decodeLegacySchema(schemaJSON)
+		if err != nil {
+			return err
+		}
+		t := &boltTx{id: string(name), tx: tx}
+		for _, v := range schema.Validations {
+			keys := legacyKeys(v.ID)
+			values := make([][]byte, len(keys))
+			for i, key := range keys {
+				values[i] = bucket.Get([]byte(key))
+			}
+			compiled, err := decodeLegacyCompiledValidation(v.ID, values)
+			if err != nil {
+				return err
+			}
+			if err := t.WriteCompiledValidation(context.Background(), v.ID, compiled); err != nil {
+				return err
+			}
+			for _, key := range keys {
+				if err := bucket.Delete([]byte(key)); err != nil {
+					return err
+				}
+			}
+		}
+		return t.WriteSchema(context.Background(), schema)
+	})
+}
+
+// Close releases the lock of the file.
+func (s *BoltStore) Close() error {
+	if err := s.db.Close(); err != nil {
+		return failure.Translate(err, appError.ErrStoreOperationFailed, failure.Messagef("failed to close bolt store"))
+	}
+	return nil
+}
+
+// Transaction runs fn with a store whose writes are committed together, or discarded if fn fails.
+func (s *BoltStore) Transaction(ctx context.Context, fn func(Store) error) error {
+	return s.update(func(t *boltTx) error {
+		return fn(t)
+	})
+}
+
+func (s *BoltStore) view(fn func(*boltTx) error) error {
+	var fnErr error
+	err := s.db.View(func(tx *bolt.Tx) error {
+		fnErr = fn(&boltTx{id: s.id, tx: tx, subscribers: s.subscribers})
+		return fnErr
+	})
+	if err != nil && fnErr == nil {
+		return failure.Translate(err, appError.ErrStoreOperationFailed, failure.Messagef("failed to read bolt store"))
+	}
+	return err
+}
+
+func (s *BoltStore) update(fn func(*boltTx) error) error {
+	var fnErr error
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		fnErr = fn(&boltTx{id: s.id, tx: tx, subscribers: s.subscribers})
+		return fnErr
+	})
+	if err != nil && fnErr == nil {
+		// The commit failed, e.g. the disk is full
+		return failure.Translate(err, appError.ErrStoreOperationFailed, failure.Messagef("failed to commit bolt store"))
+	}
+	return err
+}
+
+func (s *BoltStore) Reset(ctx context.Context) error {
+	return s.update(func(t *boltTx) error { return t.Reset(ctx) })
+}
+
+func (s *BoltStore) WriteSchema(ctx context.Context, dsl *dsl.DSL) error {
+	return s.update(func(t *boltTx) error { return t.WriteSchema(ctx, dsl) })
+}
+
+func (s *BoltStore) ReadSchema(ctx context.Context) (dsl *dsl.DSL, err error) {
+	err = s.view(func(t *boltTx) error {
+		dsl, err = t.ReadSchema(ctx)
+		return err
+	})
+	return dsl, err
+}
+
+func (s *BoltStore) WriteCompiledValidation(ctx context.Context, id string, compiled *CompiledValidation) error {
+	return s.update(func(t *boltTx) error { return t.WriteCompiledValidation(ctx, id, compiled) })
+}
+
+func (s *BoltStore) ReadCompiledValidation(ctx context.Context, id string) (compiled *CompiledValidation, err error) {
+	err = s.view(func(t *boltTx) error {
+		compiled, err = t.ReadCompiledValidation(ctx, id)
+		return err
+	})
+	return compiled, err
+}
+
+func (s *BoltStore) WriteDataset(ctx context.Context, name string, entries map[string]string) error {
+	return s.update(func(t *boltTx) error { return t.WriteDataset(ctx, name, entries) })
+}
+
+func (s *BoltStore) ReadDataset(ctx context.Context, name string) (entries map[string]string, err error) {
+	err = s.view(func(t *boltTx) error {
+		entries, err = t.ReadDataset(ctx, name)
+		return err
+	})
+	return entries, err
+}
+
+func (s *BoltStore) LookupDataset(ctx context.Context, name string, key string) (value string, found bool, err error) {
+	err = s.view(func(t *boltTx) error {
+		value, found, err = t.LookupDataset(ctx, name, key)
+		return err
+	})
+	return value, found, err
+}
+
+func (s *BoltStore) ReadFileDescriptorSet(ctx context.Context) (fileDescriptorSet []byte, err error) {
+	err = s.view(func(t *boltTx) error {
+		fileDescriptorSet, err = t.ReadFileDescriptorSet(ctx)
+		return err
+	})
+	return fileDescriptorSet, err
+}
+
+func (s *BoltStore) Notify(ctx context.Context) error {
+	return s.update(func(t *boltTx) error { return t.Notify(ctx) })
+}
+
+func (s *BoltStore) Subscribe(ctx context.Context) (<-chan string, error) {
+	return s.subscribers.subscribe(ctx), nil
+}
+
+func (s *BoltStore) ReadVersion(ctx context.Context) (version string, err error) {
+	err = s.view(func(t *boltTx) error {
+		value, err := t.get(boltVersionKey)
+		version = string(value)
+		return err
+	})
+	return version, err
+}
+
+// Namespace returns the store of the namespace, a bucket of the same file named after its ID.
+// It must not be closed, as the file is shared.
+func (s *BoltStore) Namespace(name string) Store {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	store, ok := s.namespaces[name]
+	if !ok {
+		store = &BoltStore{id: getNamespaceStoreID(s.id, name), db: s.db, subscribers: newSubscribers(), namespaces: make(map[string]*BoltStore)}
+		s.namespaces[name] = store
+	}
+	return store
+}
+
+// CreateNamespace adds the namespace to the registry of the node, a bucket with a key per namespace.
+func (s *BoltStore) CreateNamespace(ctx context.Context, namespace *Namespace) error {
+	encoded, err := encodeNamespace(namespace)
+	if err != nil {
+		return err
+	}
+	return s.update(func(t *boltTx) error {
+		registry, err := t.tx.CreateBucketIfNotExists([]byte(getNamespacesID(s.id)))
+		if err != nil {
+			return failure.Translate(err, appError.ErrStoreOperationFailed, failure.Messagef("failed to create bucket of namespaces"))
+		}
+		if registry.Get([]byte(namespace.Name)) != nil {
+			return failure.New(appError.ErrAlreadyExists, failure.Messagef("namespace %s already exists", namespace.Name))
+		}
+		if err := registry.Put([]byte(namespace.Name), encoded); err != nil {
+			return failure.Translate(err, appError.ErrStoreOperationFailed, failure.Messagef("failed to save namespace %s", namespace.Name))
+		}
+		return nil
+	})
+}
+
+func (s *BoltStore) ReadNamespace(ctx context.Context, name string) (namespace *Namespace, err error) {
+	err = s.view(func(t *boltTx) error {
+		var encoded []byte
+		if registry := t.tx.Bucket([]byte(getNamespacesID(s.id))); registry != nil {
+			encoded = registry.Get([]byte(name))
+		}
+		if encoded == nil {
+			return failure.New(appError.ErrNotFound, failure.Messagef("namespace %s not found", name))
+		}
+		namespace, err = decodeNamespace(encoded)
+		return err
+	})
+	return namespace, err
+}
+
+func (s *BoltStore) ReadNamespaces(ctx context.Context) (namespaces []*Namespace, err error) {
+	namespaces = []*Namespace{}
+	err = s.view(func(t *boltTx) error {
+		registry := t.tx.Bucket([]byte(getNamespacesID(s.id)))
+		if registry == nil {
+			return nil
+		}
+		return registry.ForEach(func(name []byte, encoded []byte) error {
+			namespace, err := decodeNamespace(encoded)
+			if err != nil {
+				return err
+			}
+			namespaces = append(namespaces, namespace)
+			return nil
+		})
+	})
+	sort.Slice(namespaces, func(i, j int) bool { return namespaces[i].Name < namespaces[j].Name })
+	return namespaces, err
+}
+
+// DeleteNamespace deletes the bucket of the namespace and removes it from the registry in a single transaction.
+func (s *BoltStore) DeleteNamespace(ctx context.Context, name string) error {
+	err := s.update(func(t *boltTx) error {
+		registry := t.tx.Bucket([]byte(getNamespacesID(s.id)))
+		if registry == nil || registry.Get([]byte(name)) == nil {
+			return failure.New(appError.ErrNotFound, failure.Messagef("namespace %s not found", name))
+		}
+		if err := registry.Delete([]byte(name)); err != nil {
+			return failure.Translate(err, appError.ErrStoreOperationFailed, failure.Messagef("failed to delete namespace %s", name))
+		}
+		namespaceTx := &boltTx{id: getNamespaceStoreID(s.id, name), tx: t.tx}
+		return namespaceTx.Reset(ctx)
+	})
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	delete(s.namespaces, name)
+	s.mu.Unlock()
+	return nil
+}
+
+// boltTx is the store of a node within a transaction.
+// Values read from the file are only valid during the transaction, so they are decoded or copied before it ends.
+type boltTx struct {
+	id          string
+	tx          *bolt.Tx
+	subscribers *subscribers
+}
+
+// bucket returns the bucket of the node, or nil if nothing is written yet in a read-only transaction.
+func (t *boltTx) bucket() (*bolt.Bucket, error) {
+	if !t.tx.Writable() {
+		return t.tx.Bucket([]byte(t.id)), nil
+	}
+	bucket, err := t.tx.CreateBucketIfNotExists([]byte(t.id))
+	if err != nil {
+		return nil, failure.Translate(err, appError.ErrStoreOperationFailed, failure.Messagef("failed to create bucket of %s", t.id))
+	}
+	return bucket, nil
+}
+
+func (t *boltTx) get(key []byte) ([]byte, error) {
+	bucket, err := t.bucket()
+	if err != nil || bucket == nil {
+		return nil, err
+	}
+	return bucket.Get(key), nil
+}
+
+func (t *boltTx) put(key []byte, value []byte) error {
+	bucket, err := t.bucket()
+	if err != nil {
+		return err
+	}
+	if err := bucket.Put(key, value); err != nil {
+		return failure.Translate(err, appError.ErrStoreOperationFailed, failure.Messagef("failed to save %s", key))
+	}
+	return nil
+}
+
+func (t *boltTx) delete(key []byte) error {
+	bucket, err := t.bucket()
+	if err != nil {
+		return err
+	}
+	if err := bucket.Delete(key); err != nil {
+		return failure.Translate(err, appError.ErrStoreOperationFailed, failure.Messagef("failed to delete %s", key))
+	}
+	return nil
+}
+
+func (t *boltTx) Reset(ctx context.Context) error {
+	if err := t.tx.DeleteBucket([]byte(t.id)); err != nil && err != bolt.ErrBucketNotFound {
+		return failure.Translate(err, appError.ErrStoreOperationFailed, failure.Messagef("failed to reset bolt store"))
+	}
+	return nil
+}
+
+func (t *boltTx) WriteSchema(ctx context.Context, dsl *dsl.DSL) error {
+	encoded, err := encodeSchema(dsl)
+	if err != nil {
+		return err
+	}
+	if err := t.put(boltSchemaKey, encoded); err != nil {
+		return err
+	}
+	if len(dsl.FileDescriptorSet) == 0 {
+		return t.delete(boltDescriptorSetKey)
+	}
+	return t.put(boltDescriptorSetKey, dsl.FileDescriptorSet)
+}
+
+func (t *boltTx) ReadSchema(ctx context.Context) (*dsl.DSL, error) {
+	encoded, err := t.get(boltSchemaKey)
+	if err != nil {
+		return nil, err
+	}
+	if encoded == nil {
+		return nil, failure.New(appError.ErrNotFound, failure.Messagef("schema not found"))
+	}
+	fileDescriptorSet, err := t.ReadFileDescriptorSet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return decodeSchema(encoded, fileDescriptorSet)
+}
+
+func (t *boltTx) WriteCompiledValidation(ctx context.Context, id string, compiled *CompiledValidation) error {
+	encoded, err := encodeCompiledValidation(id, compiled)
+	if err != nil {
+		return err
+	}
+	return t.put([]byte(boltValidationPrefix+id), encoded)
+}
+
+func (t *boltTx) ReadCompiledValidation(ctx context.Context, id string) (*CompiledValidation, error) {
+	encoded, err := t.get([]byte(boltValidationPrefix + id))
+	if err != nil {
+		return nil, err
+	}
+	if encoded == nil {
+		return nil, failure.New(appError.ErrNotFound, failure.Messagef("validation %s not found", id))
+	}
+	return decodeCompiledValidation(encoded)
+}
+
+// datasetBucket returns the bucket of the dataset, or nil if it has no entries in a read-only transaction.
+// Each dataset has its own bucket so that lookups do not decode the whole dataset.
+func (t *boltTx) datasetBucket(name string) (*bolt.Bucket, error) {
+	bucket, err := t.bucket()
+	if err != nil || bucket == nil {
+		return nil, err
+	}
+	if !t.tx.Writable() {
+		datasets := bucket.Bucket(boltDatasetsBucket)
+		if datasets == nil {
+			return nil, nil
+		}
+		return datasets.Bucket([]byte(name)), nil
+	}
+	datasets, err := bucket.CreateBucketIfNotExists(boltDatasetsBucket)
+	if err != nil {
+		return nil, failure.Translate(err, appError.ErrStoreOperationFailed, failure.Messagef("failed to create bucket of datasets"))
+	}
+	return datasets.CreateBucketIfNotExists([]byte(name))
+}
+
+func (t *boltTx) WriteDataset(ctx context.Context, name string, entries map[string]string) error {
+	bucket, err := t.bucket()
+	if err != nil {
+		return err
+	}
+	if datasets := bucket.Bucket(boltDatasetsBucket); datasets != nil {
+		if err := datasets.DeleteBucket([]byte(name)); err != nil && err != bolt.ErrBucketNotFound {
+			return failure.Translate(err, appError.ErrStoreOperationFailed, failure.Messagef("failed to save dataset %s", name))
+		}
+	}
+	dataset, err := t.datasetBucket(name)
+	if err != nil {
+		return failure.Translate(err, appError.ErrStoreOperationFailed, failure.Messagef("failed to save dataset %s", name))
+	}
+	for key, value := range entries {
+		if err := dataset.Put([]byte(key), []byte(value)); err != nil {
+			return failure.Translate(err, appError.ErrStoreOperationFailed, failure.Messagef("failed to save dataset %s", name))
+		}
+	}
+	return nil
+}
+
+func (t *boltTx) ReadDataset(ctx context.Context, name string) (map[string]string, error) {
+	dataset, err := t.datasetBucket(name)
+	if err != nil {
+		return nil, err
+	}
+	entries := make(map[string]string)
+	if dataset == nil {
+		return entries, nil
+	}
+	err = dataset.ForEach(func(key, value []byte) error {
+		entries[string(key)] = string(value)
+		return nil
+	})
+	if err != nil {
+		return nil, failure.Translate(err, appError.ErrStoreOperationFailed, failure.Messagef("failed to get dataset %s", name))
+	}
+	return entries, nil
+}
+
+func (t *boltTx) LookupDataset(ctx context.Context, name string, key string) (string, bool, error) {
+	dataset, err := t.datasetBucket(name)
+	if err != nil || dataset == nil {
+		return "", false, err
+	}
+	// Get cannot tell an empty value, which the members of a set have, from a missing key
+	k, v := dataset.Cursor().Seek([]byte(key))
+	if !bytes.Equal(k, []byte(key)) {
+		return "", false, nil
+	}
+	return string(v), true, nil
+}
+
+// Notify publishes the version once the transaction is committed.
+func (t *boltTx) Notify(ctx context.Context) error {
+	version, err := newVersion()
+	if err != nil {
+		return err
+	}
+	if err := t.put(boltVersionKey, []byte(version)); err != nil {
+		return err
+	}
+	t.tx.OnCommit(func() { t.subscribers.publish(version) })
+	return nil
+}
+
+func (t *boltTx) Subscribe(ctx context.Context) (<-chan string, error) {
+	return t.subscribers.subscribe(ctx), nil
+}
+
+func (t *boltTx) ReadVersion(ctx context.Context) (string, error) {
+	version, err := t.get(boltVersionKey)
+	return string(version), err
+}
+
+func (t *boltTx) ReadFileDescriptorSet(ctx context.Context) ([]byte, error) {
+	fileDescriptorSet, err := t.get(boltDescriptorSetKey)
+	if err != nil || fileDescriptorSet == nil {
+		return nil, err
+	}
+	return bytes.Clone(fileDescriptorSet), nil
+}
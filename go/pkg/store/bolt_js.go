@@ -0,0 +1,21 @@
+//go:build js
+
+package store
+
+import (
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+)
+
+// BoltStore is unsupported on js, where bbolt cannot map its file into memory.
+type BoltStore struct {
+	Store
+}
+
+func NewBoltStore(id string, path string) (*BoltStore, error) {
+	return nil, failure.New(appError.ErrConfigError, failure.Messagef("bolt store is not supported on js"))
+}
+
+func (s *BoltStore) Close() error {
+	return nil
+}
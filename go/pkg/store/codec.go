@@ -0,0 +1,207 @@
+package store
+
+import (
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/dsl"
+	pb "github.com/shibukazu/open-ve/go/proto/store/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// formatVersion is the version of the encoding of the records, incremented on incompatible changes.
+// The records written before it was introduced are JSON and are migrated by the persistent stores.
+const formatVersion = 1
+
+// encodeSchema encodes the schema without the file descriptor set, which the stores keep in a record of its own.
+func encodeSchema(schema *dsl.DSL) ([]byte, error) {
+	s, err := schemaToProto(schema)
+	if err != nil {
+		return nil, err
+	}
+	data, err := proto.Marshal(s)
+	if err != nil {
+		return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to encode schema"))
+	}
+	return data, nil
+}
+
+func schemaToProto(schema *dsl.DSL) (*pb.Schema, error) {
+	s := &pb.Schema{FormatVersion: formatVersion}
+	for _, v := range schema.Validations {
+		validation := &pb.Validation{Id: v.ID, Cels: v.Cels, Variables: encodeVariables(v.Variables)}
+		for _, computed := range v.Computed {
+			validation.Computed = append(validation.Computed, &pb.ComputedVariable{Name: computed.Name, Cel: computed.Cel})
+		}
+		for _, profile := range v.Profiles {
+			validation.Profiles = append(validation.Profiles, &pb.Profile{
+				Name:     profile.Name,
+				Cels:     profile.Cels,
+				Exclude:  profile.Exclude,
+				Optional: profile.Optional,
+			})
+		}
+		for _, testCase := range v.TestCases {
+			tc := &pb.TestCase{Name: testCase.Name, Profile: testCase.Profile, Expected: testCase.Expected}
+			for _, variable := range testCase.Variables {
+				value, err := structpb.NewValue(variable.Value)
+				if err != nil {
+					return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("invalid value of %s in test case %s", variable.Name, testCase.Name))
+				}
+				tc.Variables = append(tc.Variables, &pb.TestVariable{Name: variable.Name, Value: value})
+			}
+			validation.TestCases = append(validation.TestCases, tc)
+		}
+		s.Validations = append(s.Validations, validation)
+	}
+	for _, d := range schema.Datasets {
+		s.Datasets = append(s.Datasets, &pb.Dataset{Name: d.Name, Kind: d.Kind, Hash: d.Hash})
+	}
+	s.Version = schema.Version
+	return s, nil
+}
+
+// decodeSchema decodes the schema and sets the file descriptor set kept apart from it.
+func decodeSchema(data []byte, fileDescriptorSet []byte) (*dsl.DSL, error) {
+	s := &pb.Schema{}
+	if err := proto.Unmarshal(data, s); err != nil {
+		return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to decode schema"))
+	}
+	if s.FormatVersion > formatVersion {
+		return nil, failure.New(appError.ErrStoreOperationFailed, failure.Messagef("unsupported schema format version: %d", s.FormatVersion))
+	}
+	schema := &dsl.DSL{FileDescriptorSet: fileDescriptorSet, Version: s.Version}
+	for _, v := range s.Validations {
+		validation := dsl.Validation{ID: v.Id, Cels: v.Cels, Variables: decodeVariables(v.Variables)}
+		for _, computed := range v.Computed {
+			validation.Computed = append(validation.Computed, dsl.ComputedVariable{Name: computed.Name, Cel: computed.Cel})
+		}
+		for _, profile := range v.Profiles {
+			validation.Profiles = append(validation.Profiles, dsl.Profile{
+				Name:     profile.Name,
+				Cels:     profile.Cels,
+				Exclude:  profile.Exclude,
+				Optional: profile.Optional,
+			})
+		}
+		for _, tc := range v.TestCases {
+			testCase := dsl.TestCase{Name: tc.Name, Profile: tc.Profile, Expected: tc.Expected}
+			for _, variable := range tc.Variables {
+				testCase.Variables = append(testCase.Variables, dsl.TestVeriable{Name: variable.Name, Value: variable.Value.AsInterface()})
+			}
+			validation.TestCases = append(validation.TestCases, testCase)
+		}
+		schema.Validations = append(schema.Validations, validation)
+	}
+	for _, d := range s.Datasets {
+		schema.Datasets = append(schema.Datasets, dsl.Dataset{Name: d.Name, Kind: d.Kind, Hash: d.Hash})
+	}
+	return schema, nil
+}
+
+func encodeCompiledValidation(id string, compiled *CompiledValidation) ([]byte, error) {
+	c := &pb.CompiledValidation{
+		FormatVersion: formatVersion,
+		Id:            id,
+		Variables:     encodeVariables(compiled.Variables),
+		CheckedExprs:  compiled.AllEncodedAST,
+		CelVersion:    compiled.CELVersion,
+	}
+	if !compiled.CompileTime.IsZero() {
+		c.CompileTime = timestamppb.New(compiled.CompileTime)
+	}
+	for _, computed := range compiled.Computed {
+		c.Computed = append(c.Computed, &pb.CompiledComputedVariable{Name: computed.Name, CheckedExpr: computed.EncodedAST})
+	}
+	for _, profile := range compiled.Profiles {
+		p := &pb.CompiledProfile{Name: profile.Name, CheckedExprs: profile.AllEncodedAST, Optional: profile.Optional}
+		for _, index := range profile.Exclude {
+			p.Exclude = append(p.Exclude, int32(index))
+		}
+		c.Profiles = append(c.Profiles, p)
+	}
+	data, err := proto.Marshal(c)
+	if err != nil {
+		return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to encode validation %s", id))
+	}
+	return data, nil
+}
+
+func decodeCompiledValidation(data []byte) (*CompiledValidation, error) {
+	c := &pb.CompiledValidation{}
+	if err := proto.Unmarshal(data, c); err != nil {
+		return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to decode validation"))
+	}
+	if c.FormatVersion > formatVersion {
+		return nil, failure.New(appError.ErrStoreOperationFailed, failure.Messagef("unsupported format version %d of validation %s", c.FormatVersion, c.Id))
+	}
+	compiled := &CompiledValidation{
+		Variables:     decodeVariables(c.Variables),
+		AllEncodedAST: c.CheckedExprs,
+		Computed:      make([]EncodedComputedVariable, 0, len(c.Computed)),
+		Profiles:      make([]EncodedProfile, 0, len(c.Profiles)),
+		CELVersion:    c.CelVersion,
+	}
+	if c.CompileTime != nil {
+		compiled.CompileTime = c.CompileTime.AsTime()
+	}
+	for _, computed := range c.Computed {
+		compiled.Computed = append(compiled.Computed, EncodedComputedVariable{Name: computed.Name, EncodedAST: computed.CheckedExpr})
+	}
+	for _, p := range c.Profiles {
+		profile := EncodedProfile{Name: p.Name, AllEncodedAST: p.CheckedExprs, Optional: p.Optional}
+		for _, index := range p.Exclude {
+			profile.Exclude = append(profile.Exclude, int(index))
+		}
+		compiled.Profiles = append(compiled.Profiles, profile)
+	}
+	return compiled, nil
+}
+
+func encodeVariables(variables []dsl.Variable) []*pb.Variable {
+	encoded := make([]*pb.Variable, 0, len(variables))
+	for _, v := range variables {
+		encoded = append(encoded, &pb.Variable{Name: v.Name, Type: v.Type, Path: v.Path})
+	}
+	return encoded
+}
+
+func decodeVariables(variables []*pb.Variable) []dsl.Variable {
+	decoded := make([]dsl.Variable, 0, len(variables))
+	for _, v := range variables {
+		decoded = append(decoded, dsl.Variable{Name: v.Name, Type: v.Type, Path: v.Path})
+	}
+	return decoded
+}
+
+func encodeNamespace(namespace *Namespace) ([]byte, error) {
+	n := &pb.Namespace{
+		FormatVersion:     formatVersion,
+		Name:              namespace.Name,
+		PresharedKeyHash:  namespace.PresharedKeyHash,
+		PrivilegedKeyHash: namespace.PrivilegedKeyHash,
+		CreateTime:        timestamppb.New(namespace.CreatedAt),
+	}
+	data, err := proto.Marshal(n)
+	if err != nil {
+		return nil, failure.Translate(err, appError.ErrServerError, failure.Messagef("failed to encode namespace %s", namespace.Name))
+	}
+	return data, nil
+}
+
+func decodeNamespace(data []byte) (*Namespace, error) {
+	n := &pb.Namespace{}
+	if err := proto.Unmarshal(data, n); err != nil {
+		return nil, failure.Translate(err, appError.ErrStoreOperationFailed, failure.Messagef("failed to decode namespace"))
+	}
+	if n.FormatVersion > formatVersion {
+		return nil, failure.New(appError.ErrStoreOperationFailed, failure.Messagef("unsupported format version %d of namespace %s", n.FormatVersion, n.Name))
+	}
+	return &Namespace{
+		Name:              n.Name,
+		PresharedKeyHash:  n.PresharedKeyHash,
+		PrivilegedKeyHash: n.PrivilegedKeyHash,
+		CreatedAt:         n.CreateTime.AsTime(),
+	}, nil
+}
@@ -0,0 +1,55 @@
+package store
+
+import (
+	"encoding/json"
+
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/dsl"
+)
+
+// Before formatVersion was introduced, the schema was JSON and each validation was kept in four JSON keys.
+// These are only read to migrate them.
+const (
+	legacyVariablesKey = "variables:"
+	legacyASTKey       = "ast:"
+	legacyComputedKey  = "computed:"
+	legacyProfilesKey  = "profiles:"
+)
+
+// legacyKeys are the keys of a validation in the legacy format, relative to the node.
+func legacyKeys(id string) []string {
+	return []string{legacyVariablesKey + id, legacyASTKey + id, legacyComputedKey + id, legacyProfilesKey + id}
+}
+
+// isLegacySchema reports whether the schema is JSON. The encoded schema starts with the tag of its format version.
+func isLegacySchema(data []byte) bool {
+	return len(data) > 0 && data[0] == '{'
+}
+
+func decodeLegacySchema(data []byte) (*dsl.DSL, error) {
+	schema := &dsl.DSL{}
+	if err := json.Unmarshal(data, schema); err != nil {
+		return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to decode legacy schema from json"))
+	}
+	return schema, nil
+}
+
+// decodeLegacyCompiledValidation decodes the values of the legacy keys of a validation, in the order of legacyKeys.
+// The rules, computed variables and profiles were not written if empty, so their values may be nil.
+func decodeLegacyCompiledValidation(id string, values [][]byte) (*CompiledValidation, error) {
+	compiled := &CompiledValidation{
+		Computed: []EncodedComputedVariable{},
+		Profiles: []EncodedProfile{},
+	}
+	targets := []interface{}{&compiled.Variables, &compiled.AllEncodedAST, &compiled.Computed, &compiled.Profiles}
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+		if err := json.Unmarshal(value, targets[i]); err != nil {
+			return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to decode legacy validation %s from json", id))
+		}
+	}
+	return compiled, nil
+}
@@ -1,8 +1,8 @@
 package store
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"sort"
 	"strings"
 	"sync"
 
@@ -14,100 +14,204 @@ import (
 type MemoryStore struct {
 	id     string
 	memory map[string][]byte
-	mu     sync.RWMutex
+	// datasets are kept decoded so that lookups do not decode the whole dataset
+	datasets    map[string]map[string]string
+	version     string
+	subscribers *subscribers
+	// namespaces are the registry of the namespaces and namespaceStores their stores.
+	namespaces      map[string]*Namespace
+	namespaceStores map[string]*MemoryStore
+	mu              sync.RWMutex
 }
 
 func NewMemoryStore(id string) *MemoryStore {
 	mamory := make(map[string][]byte)
-	return &MemoryStore{id: id, memory: mamory}
+	return &MemoryStore{
+		id:              id,
+		memory:          mamory,
+		datasets:        make(map[string]map[string]string),
+		subscribers:     newSubscribers(),
+		namespaces:      make(map[string]*Namespace),
+		namespaceStores: make(map[string]*MemoryStore),
+	}
 }
 
-func (s *MemoryStore) Reset() error {
+func (s *MemoryStore) Reset(ctx context.Context) error {
 	s.mu.Lock()
 	for k := range s.memory {
 		if strings.HasPrefix(k, s.id+":") {
 			delete(s.memory, k)
 		}
 	}
+	for k := range s.datasets {
+		if strings.HasPrefix(k, s.id+":") {
+			delete(s.datasets, k)
+		}
+	}
 	s.mu.Unlock()
 	return nil
 }
 
-func (s *MemoryStore) WriteSchema(dsl *dsl.DSL) error {
-	var dslJson bytes.Buffer
-	enc := json.NewEncoder(&dslJson)
-	enc.SetEscapeHTML(false)
-	if err := enc.Encode(dsl); err != nil {
-		return failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to encode dsl to json"))
+func (s *MemoryStore) WriteSchema(ctx context.Context, dsl *dsl.DSL) error {
+	encoded, err := encodeSchema(dsl)
+	if err != nil {
+		return err
 	}
 	s.mu.Lock()
-	s.memory[s.id+":schema"] = dslJson.Bytes()
+	s.memory[s.id+":schema"] = encoded
+	if len(dsl.FileDescriptorSet) == 0 {
+		delete(s.memory, getFileDescriptorSetID(s.id))
+	} else {
+		s.memory[getFileDescriptorSetID(s.id)] = dsl.FileDescriptorSet
+	}
 	s.mu.Unlock()
 
 	return nil
 }
 
-func (s *MemoryStore) ReadSchema() (*dsl.DSL, error) {
-	dsl := &dsl.DSL{}
+func (s *MemoryStore) ReadSchema(ctx context.Context) (*dsl.DSL, error) {
 	s.mu.RLock()
-	dslJSON, ok := s.memory[s.id+":schema"]
+	encoded, ok := s.memory[s.id+":schema"]
+	fileDescriptorSet := s.memory[getFileDescriptorSetID(s.id)]
 	s.mu.RUnlock()
 	if !ok {
-		return nil, failure.New(appError.ErrStoreOperationFailed, failure.Messagef("schema not found"))
+		return nil, failure.New(appError.ErrNotFound, failure.Messagef("schema not found"))
 	}
-
-	if err := json.Unmarshal(dslJSON, dsl); err != nil {
-		return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to decode dsl from json"))
-	}
-	return dsl, nil
+	return decodeSchema(encoded, fileDescriptorSet)
 }
 
-func (s *MemoryStore) WriteVariables(id string, variables []dsl.Variable) error {
-	variablesJson, err := json.Marshal(variables)
+func (s *MemoryStore) WriteCompiledValidation(ctx context.Context, id string, compiled *CompiledValidation) error {
+	encoded, err := encodeCompiledValidation(id, compiled)
 	if err != nil {
-		return failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to encode variables to json"))
+		return err
 	}
 	s.mu.Lock()
-	s.memory[getVariablesID(s.id, id)] = variablesJson
+	s.memory[getCompiledValidationID(s.id, id)] = encoded
 	s.mu.Unlock()
 	return nil
 }
 
-func (s *MemoryStore) ReadVariables(id string) ([]dsl.Variable, error) {
+func (s *MemoryStore) ReadCompiledValidation(ctx context.Context, id string) (*CompiledValidation, error) {
 	s.mu.RLock()
-	variablesJSON, ok := s.memory[getVariablesID(s.id, id)]
+	encoded, ok := s.memory[getCompiledValidationID(s.id, id)]
 	s.mu.RUnlock()
 	if !ok {
-		return nil, failure.New(appError.ErrStoreOperationFailed, failure.Messagef("variables not found"))
+		return nil, failure.New(appError.ErrNotFound, failure.Messagef("validation %s not found", id))
 	}
+	return decodeCompiledValidation(encoded)
+}
 
-	var variables []dsl.Variable
-	if err := json.Unmarshal(variablesJSON, &variables); err != nil {
-		return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to decode variables from json"))
+func (s *MemoryStore) WriteDataset(ctx context.Context, name string, entries map[string]string) error {
+	copied := make(map[string]string, len(entries))
+	for key, value := range entries {
+		copied[key] = value
 	}
-	return variables, nil
+	s.mu.Lock()
+	s.datasets[getDatasetID(s.id, name)] = copied
+	s.mu.Unlock()
+	return nil
 }
 
-func (s *MemoryStore) WriteAllEncodedAST(id string, allEncodedAST [][]byte) error {
-	if len(allEncodedAST) == 0 {
-		return nil
+func (s *MemoryStore) ReadDataset(ctx context.Context, name string) (map[string]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entries := s.datasets[getDatasetID(s.id, name)]
+	copied := make(map[string]string, len(entries))
+	for key, value := range entries {
+		copied[key] = value
 	}
-	jsonEncodedAllEncodedAST, err := jsonEncodeAllEncodedAST(allEncodedAST)
+	return copied, nil
+}
+
+func (s *MemoryStore) LookupDataset(ctx context.Context, name string, key string) (string, bool, error) {
+	s.mu.RLock()
+	value, found := s.datasets[getDatasetID(s.id, name)][key]
+	s.mu.RUnlock()
+	return value, found, nil
+}
+
+func (s *MemoryStore) ReadFileDescriptorSet(ctx context.Context) ([]byte, error) {
+	s.mu.RLock()
+	fileDescriptorSet := s.memory[getFileDescriptorSetID(s.id)]
+	s.mu.RUnlock()
+	return fileDescriptorSet, nil
+}
+
+func (s *MemoryStore) Notify(ctx context.Context) error {
+	version, err := newVersion()
 	if err != nil {
 		return err
 	}
 	s.mu.Lock()
-	s.memory[getAstID(s.id, id)] = jsonEncodedAllEncodedAST
+	s.version = version
 	s.mu.Unlock()
+	s.subscribers.publish(version)
 	return nil
 }
 
-func (s *MemoryStore) ReadAllEncodedAST(id string) ([][]byte, error) {
+func (s *MemoryStore) Subscribe(ctx context.Context) (<-chan string, error) {
+	return s.subscribers.subscribe(ctx), nil
+}
+
+func (s *MemoryStore) ReadVersion(ctx context.Context) (string, error) {
 	s.mu.RLock()
-	jsonEncodedAllEncodedAST, ok := s.memory[getAstID(s.id, id)]
-	s.mu.RUnlock()
+	defer s.mu.RUnlock()
+	return s.version, nil
+}
+
+// Namespace returns the same store for the same name, as its contents are only in memory.
+func (s *MemoryStore) Namespace(name string) Store {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	store, ok := s.namespaceStores[name]
 	if !ok {
-		return nil, failure.New(appError.ErrStoreOperationFailed)
+		store = NewMemoryStore(getNamespaceStoreID(s.id, name))
+		s.namespaceStores[name] = store
 	}
-	return jsonDecodeAllEncodedAST(jsonEncodedAllEncodedAST)
+	return store
+}
+
+func (s *MemoryStore) CreateNamespace(ctx context.Context, namespace *Namespace) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.namespaces[namespace.Name]; ok {
+		return failure.New(appError.ErrAlreadyExists, failure.Messagef("namespace %s already exists", namespace.Name))
+	}
+	copied := *namespace
+	s.namespaces[namespace.Name] = &copied
+	return nil
+}
+
+func (s *MemoryStore) ReadNamespace(ctx context.Context, name string) (*Namespace, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	namespace, ok := s.namespaces[name]
+	if !ok {
+		return nil, failure.New(appError.ErrNotFound, failure.Messagef("namespace %s not found", name))
+	}
+	copied := *namespace
+	return &copied, nil
+}
+
+func (s *MemoryStore) ReadNamespaces(ctx context.Context) ([]*Namespace, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	namespaces := make([]*Namespace, 0, len(s.namespaces))
+	for _, namespace := range s.namespaces {
+		copied := *namespace
+		namespaces = append(namespaces, &copied)
+	}
+	sort.Slice(namespaces, func(i, j int) bool { return namespaces[i].Name < namespaces[j].Name })
+	return namespaces, nil
+}
+
+func (s *MemoryStore) DeleteNamespace(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.namespaces[name]; !ok {
+		return failure.New(appError.ErrNotFound, failure.Messagef("namespace %s not found", name))
+	}
+	delete(s.namespaces, name)
+	delete(s.namespaceStores, name)
+	return nil
 }
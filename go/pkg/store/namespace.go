@@ -0,0 +1,30 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// Namespace is a tenant of a node with a schema, datasets and a version of its own.
+type Namespace struct {
+	Name string
+	// PresharedKeyHash and PrivilegedKeyHash are the SHA-256 hashes of the keys accepted for the namespace
+	// in addition to those of the node. Empty if not set.
+	PresharedKeyHash  []byte
+	PrivilegedKeyHash []byte
+	CreatedAt         time.Time
+}
+
+// Namespacer is implemented by stores keeping the namespaces of a node, each in a store of its own
+// sharing the backend of the node.
+type Namespacer interface {
+	// Namespace returns the store of the namespace. It does not check that the namespace exists.
+	Namespace(name string) Store
+	// CreateNamespace fails with appError.ErrAlreadyExists if the namespace exists.
+	CreateNamespace(context.Context, *Namespace) error
+	ReadNamespace(context.Context, string) (*Namespace, error)
+	// ReadNamespaces returns the namespaces sorted by name.
+	ReadNamespaces(context.Context) ([]*Namespace, error)
+	// DeleteNamespace deletes the namespace with its schema and datasets.
+	DeleteNamespace(context.Context, string) error
+}
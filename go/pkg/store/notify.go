@@ -0,0 +1,58 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+)
+
+// newVersion returns a random version, so that a version is never reused even after the store is reset.
+func newVersion() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", failure.Translate(err, appError.ErrServerError, failure.Messagef("failed to generate version"))
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// subscribers delivers the versions to the subscribers within the process.
+type subscribers struct {
+	channels map[chan string]struct{}
+	mu       sync.Mutex
+}
+
+func newSubscribers() *subscribers {
+	return &subscribers{channels: make(map[chan string]struct{})}
+}
+
+// publish does not wait for slow subscribers. A subscriber that has not received the previous version
+// is notified of the change anyway, and reads the version again.
+func (s *subscribers) publish(version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.channels {
+		select {
+		case ch <- version:
+		default:
+		}
+	}
+}
+
+func (s *subscribers) subscribe(ctx context.Context) <-chan string {
+	ch := make(chan string, 1)
+	s.mu.Lock()
+	s.channels[ch] = struct{}{}
+	s.mu.Unlock()
+	go func() {
+		<-ctx.Done()
+		s.mu.Lock()
+		delete(s.channels, ch)
+		close(ch)
+		s.mu.Unlock()
+	}()
+	return ch
+}
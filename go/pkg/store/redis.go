@@ -1,109 +1,404 @@
 package store
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"sync"
 
-	"github.com/go-redis/redis"
 	"github.com/morikuni/failure/v2"
+	"github.com/redis/go-redis/v9"
 	"github.com/shibukazu/open-ve/go/pkg/appError"
 	"github.com/shibukazu/open-ve/go/pkg/dsl"
 )
 
 type RedisStore struct {
-	id          string
-	redisClient *redis.Client
+	id string
+	// keyPrefix is the node ID, hash-tagged as {id} on a cluster so that all keys of the node are in the same slot.
+	keyPrefix   string
+	redisClient redis.UniversalClient
 }
 
-func NewRedisStore(id string, redisClient *redis.Client) *RedisStore {
-	return &RedisStore{id: id, redisClient: redisClient}
+func NewRedisStore(id string, redisClient redis.UniversalClient) *RedisStore {
+	keyPrefix := id
+	if _, ok := redisClient.(*redis.ClusterClient); ok {
+		keyPrefix = "{" + id + "}"
+	}
+	return &RedisStore{id: id, keyPrefix: keyPrefix, redisClient: redisClient}
 }
 
-func (s *RedisStore) Reset() error {
-	keys, _ := s.redisClient.Scan(0, s.id+":*", 0).Val()
-	if len(keys) == 0 {
-		return nil
+// translateRedisError classifies the error of a command: connection errors, timeouts and states in which
+// the server cannot serve yet (e.g. loading the dataset or a failover) are ErrUnavailable,
+// an aborted transaction is ErrConflict and the others are ErrStoreOperationFailed.
+func translateRedisError(err error, opts ...failure.Field) error {
+	var netErr net.Error
+	switch {
+	case err == redis.TxFailedErr:
+		return failure.Translate(err, appError.ErrConflict, opts...)
+	case errors.As(err, &netErr),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, context.Canceled),
+		errors.Is(err, context.DeadlineExceeded),
+		isUnavailableReply(err):
+		return failure.Translate(err, appError.ErrUnavailable, opts...)
+	default:
+		return failure.Translate(err, appError.ErrStoreOperationFailed, opts...)
 	}
-	if err := s.redisClient.Del(keys...).Err(); err != nil {
-		return failure.Translate(err, appError.ErrStoreOperationFailed, failure.Messagef("failed to reset redis store"))
+}
+
+// unavailableReplyPrefixes are the error replies of a server that is temporarily unable to serve the command.
+var unavailableReplyPrefixes = []string{"LOADING ", "BUSY ", "TRYAGAIN ", "CLUSTERDOWN ", "MASTERDOWN ", "READONLY ", "redis: connection pool timeout", "redis: client is closed"}
+
+func isUnavailableReply(err error) bool {
+	for _, prefix := range unavailableReplyPrefixes {
+		if strings.HasPrefix(err.Error(), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *RedisStore) Reset(ctx context.Context) error {
+	keys, err := s.scanKeys(ctx)
+	if err != nil {
+		return translateRedisError(err, failure.Messagef("failed to scan redis store"))
+	}
+	for start := 0; start < len(keys); start += deleteBatchSize {
+		end := start + deleteBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		if err := s.redisClient.Del(ctx, keys[start:end]...).Err(); err != nil {
+			return translateRedisError(err, failure.Messagef("failed to reset redis store"))
+		}
 	}
 	return nil
 }
 
-func (s *RedisStore) WriteSchema(dsl *dsl.DSL) error {
-	var dslJson bytes.Buffer
-	enc := json.NewEncoder(&dslJson)
-	enc.SetEscapeHTML(false)
-	if err := enc.Encode(dsl); err != nil {
-		return failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to encode dsl to json"))
+const (
+	scanCount       = 1000
+	deleteBatchSize = 1000
+)
+
+// scanKeys iterates the cursor to the end to find all keys of the node.
+// SCAN only covers the node it is sent to, so every master of a cluster is scanned.
+func (s *RedisStore) scanKeys(ctx context.Context) ([]string, error) {
+	pattern := s.keyPrefix + ":*"
+	scan := func(ctx context.Context, client *redis.Client) ([]string, error) {
+		var keys []string
+		iter := client.Scan(ctx, 0, pattern, scanCount).Iterator()
+		for iter.Next(ctx) {
+			keys = append(keys, iter.Val())
+		}
+		return keys, iter.Err()
+	}
+
+	switch c := s.redisClient.(type) {
+	case *redis.Client:
+		return scan(ctx, c)
+	case *redis.ClusterClient:
+		var keys []string
+		mu := &sync.Mutex{}
+		err := c.ForEachMaster(ctx, func(ctx context.Context, master *redis.Client) error {
+			masterKeys, err := scan(ctx, master)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			keys = append(keys, masterKeys...)
+			mu.Unlock()
+			return nil
+		})
+		return keys, err
+	default:
+		return nil, failure.New(appError.ErrStoreOperationFailed, failure.Messagef("unsupported redis client: %T", c))
+	}
+}
+
+func (s *RedisStore) WriteSchema(ctx context.Context, dsl *dsl.DSL) error {
+	encoded, err := encodeSchema(dsl)
+	if err != nil {
+		return err
+	}
+	// The keys of a node are in the same slot on a cluster
+	pipe := s.redisClient.TxPipeline()
+	pipe.Set(ctx, s.keyPrefix+":schema", encoded, 0)
+	if len(dsl.FileDescriptorSet) == 0 {
+		pipe.Del(ctx, getFileDescriptorSetID(s.keyPrefix))
+	} else {
+		pipe.Set(ctx, getFileDescriptorSetID(s.keyPrefix), dsl.FileDescriptorSet, 0)
 	}
-	if err := s.redisClient.Set(s.id+":schema", dslJson.String(), 0).Err(); err != nil {
-		return failure.Translate(err, appError.ErrStoreOperationFailed, failure.Messagef("failed to save schema"))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return translateRedisError(err, failure.Messagef("failed to save schema"))
 	}
 	return nil
 }
 
-func (s *RedisStore) ReadSchema() (*dsl.DSL, error) {
-	dsl := &dsl.DSL{}
-	dslJSON, err := s.redisClient.Get(s.id + ":schema").Bytes()
+func (s *RedisStore) ReadSchema(ctx context.Context) (*dsl.DSL, error) {
+	// The keys of a node are in the same slot on a cluster
+	results, err := s.redisClient.MGet(ctx, s.keyPrefix+":schema", getFileDescriptorSetID(s.keyPrefix)).Result()
+	if err != nil {
+		return nil, translateRedisError(err, failure.Messagef("failed to get schema"))
+	}
+	encoded, ok := results[0].(string)
+	if !ok {
+		return nil, failure.New(appError.ErrNotFound, failure.Messagef("schema not found"))
+	}
+	var fileDescriptorSet []byte
+	if value, ok := results[1].(string); ok {
+		fileDescriptorSet = []byte(value)
+	}
+	return decodeSchema([]byte(encoded), fileDescriptorSet)
+}
+
+func (s *RedisStore) WriteCompiledValidation(ctx context.Context, id string, compiled *CompiledValidation) error {
+	encoded, err := encodeCompiledValidation(id, compiled)
 	if err != nil {
-		return nil, failure.Translate(err, appError.ErrStoreOperationFailed, failure.Messagef("failed to get schema"))
+		return err
+	}
+	if err := s.redisClient.Set(ctx, getCompiledValidationID(s.keyPrefix, id), encoded, 0).Err(); err != nil {
+		return translateRedisError(err, failure.Messagef("failed to save validation %s", id))
 	}
+	return nil
+}
 
-	if err := json.Unmarshal(dslJSON, dsl); err != nil {
-		return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to decode dsl from json"))
+func (s *RedisStore) ReadCompiledValidation(ctx context.Context, id string) (*CompiledValidation, error) {
+	encoded, err := s.redisClient.Get(ctx, getCompiledValidationID(s.keyPrefix, id)).Bytes()
+	if err == redis.Nil {
+		return nil, failure.New(appError.ErrNotFound, failure.Messagef("validation %s not found", id))
 	}
-	return dsl, nil
+	if err != nil {
+		return nil, translateRedisError(err, failure.Messagef("failed to get validation %s", id))
+	}
+	return decodeCompiledValidation(encoded)
 }
 
-func (s *RedisStore) WriteVariables(id string, variables []dsl.Variable) error {
-	variablesJson, err := json.Marshal(variables)
+// Migrate rewrites the DSL of the node registered by a version that stored it as JSON.
+// It does nothing if the DSL is already in the current format, so that every node can run it on startup.
+func (s *RedisStore) Migrate(ctx context.Context) error {
+	schemaJSON, err := s.redisClient.Get(ctx, s.keyPrefix+":schema").Bytes()
+	if err == redis.Nil {
+		return nil
+	}
+	if err != nil {
+		return translateRedisError(err, failure.Messagef("failed to get schema"))
+	}
+	if !isLegacySchema(schemaJSON) {
+		return nil
+	}
+	schema, err := decodeLegacySchema(schemaJSON)
 	if err != nil {
-		return failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to encode variables to json"))
+		return err
+	}
+	encodedSchema, err := encodeSchema(schema)
+	if err != nil {
+		return err
+	}
+
+	pipe := s.redisClient.TxPipeline()
+	for _, v := range schema.Validations {
+		keys := legacyKeys(v.ID)
+		for i, key := range keys {
+			keys[i] = s.keyPrefix + ":" + key
+		}
+		// The keys of a node are in the same slot on a cluster
+		results, err := s.redisClient.MGet(ctx, keys...).Result()
+		if err != nil {
+			return translateRedisError(err, failure.Messagef("failed to get legacy validation %s", v.ID))
+		}
+		values := make([][]byte, len(results))
+		for i, result := range results {
+			if value, ok := result.(string); ok {
+				values[i] = []byte(value)
+			}
+		}
+		compiled, err := decodeLegacyCompiledValidation(v.ID, values)
+		if err != nil {
+			return err
+		}
+		encoded, err := encodeCompiledValidation(v.ID, compiled)
+		if err != nil {
+			return err
+		}
+		pipe.Set(ctx, getCompiledValidationID(s.keyPrefix, v.ID), encoded, 0)
+		pipe.Del(ctx, keys...)
 	}
-	if err := s.redisClient.Set(getVariablesID(s.id, id), variablesJson, 0).Err(); err != nil {
-		return failure.Translate(err, appError.ErrStoreOperationFailed, failure.Messagef("failed to save variables"))
+	pipe.Set(ctx, s.keyPrefix+":schema", encodedSchema, 0)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return translateRedisError(err, failure.Messagef("failed to migrate redis store"))
 	}
 	return nil
 }
 
-func (s *RedisStore) ReadVariables(id string) ([]dsl.Variable, error) {
-	variablesJson, err := s.redisClient.Get(getVariablesID(s.id, id)).Bytes()
+func (s *RedisStore) WriteDataset(ctx context.Context, name string, entries map[string]string) error {
+	key := getDatasetID(s.keyPrefix, name)
+	fields := make(map[string]interface{}, len(entries))
+	for field, value := range entries {
+		fields[field] = value
+	}
+	pipe := s.redisClient.TxPipeline()
+	pipe.Del(ctx, key)
+	if len(fields) != 0 {
+		pipe.HSet(ctx, key, fields)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return translateRedisError(err, failure.Messagef("failed to save dataset %s", name))
+	}
+	return nil
+}
+
+func (s *RedisStore) ReadDataset(ctx context.Context, name string) (map[string]string, error) {
+	entries, err := s.redisClient.HGetAll(ctx, getDatasetID(s.keyPrefix, name)).Result()
 	if err != nil {
-		return nil, failure.Translate(err, appError.ErrStoreOperationFailed, failure.Messagef("failed to get variables"))
+		return nil, translateRedisError(err, failure.Messagef("failed to get dataset %s", name))
 	}
+	return entries, nil
+}
 
-	var variables []dsl.Variable
-	if err := json.Unmarshal(variablesJson, &variables); err != nil {
-		return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to decode variables from json"))
+func (s *RedisStore) LookupDataset(ctx context.Context, name string, key string) (string, bool, error) {
+	value, err := s.redisClient.HGet(ctx, getDatasetID(s.keyPrefix, name), key).Result()
+	if err == redis.Nil {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, translateRedisError(err, failure.Messagef("failed to look up dataset %s", name))
 	}
-	return variables, nil
+	return value, true, nil
 }
 
-func (s *RedisStore) WriteAllEncodedAST(id string, allEncodedAST [][]byte) error {
-	if len(allEncodedAST) == 0 {
-		return nil
+func (s *RedisStore) ReadFileDescriptorSet(ctx context.Context) ([]byte, error) {
+	fileDescriptorSet, err := s.redisClient.Get(ctx, getFileDescriptorSetID(s.keyPrefix)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, translateRedisError(err, failure.Messagef("failed to get file descriptor set"))
 	}
+	return fileDescriptorSet, nil
+}
 
-	jsonEncodedAllEncodedAST, err := jsonEncodeAllEncodedAST(allEncodedAST)
+// Notify sets the version and publishes it to the replicas sharing the node.
+func (s *RedisStore) Notify(ctx context.Context) error {
+	version, err := newVersion()
 	if err != nil {
 		return err
 	}
+	if err := s.redisClient.Set(ctx, s.keyPrefix+":version", version, 0).Err(); err != nil {
+		return translateRedisError(err, failure.Messagef("failed to save version"))
+	}
+	if err := s.redisClient.Publish(ctx, s.keyPrefix+":changes", version).Err(); err != nil {
+		return translateRedisError(err, failure.Messagef("failed to publish version"))
+	}
+	return nil
+}
+
+// Subscribe subscribes to the versions with SUBSCRIBE. go-redis reconnects and subscribes again
+// if the connection is lost, but the versions published in between are not received.
+func (s *RedisStore) Subscribe(ctx context.Context) (<-chan string, error) {
+	pubsub := s.redisClient.Subscribe(ctx, s.keyPrefix+":changes")
+	// Wait for the confirmation so that no version published after Subscribe returns is missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, translateRedisError(err, failure.Messagef("failed to subscribe to changes"))
+	}
+	versions := make(chan string, 1)
+	go func() {
+		defer close(versions)
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				pubsub.Close()
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				select {
+				case versions <- message.Payload:
+				default:
+				}
+			}
+		}
+	}()
+	return versions, nil
+}
+
+func (s *RedisStore) ReadVersion(ctx context.Context) (string, error) {
+	version, err := s.redisClient.Get(ctx, s.keyPrefix+":version").Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	if err != nil {
+		return "", translateRedisError(err, failure.Messagef("failed to get version"))
+	}
+	return version, nil
+}
 
-	if err := s.redisClient.Set(getAstID(s.id, id), jsonEncodedAllEncodedAST, 0).Err(); err != nil {
-		return failure.Translate(err, appError.ErrStoreOperationFailed, failure.Messagef("failed to save all encoded AST"))
+// Namespace returns the store of the namespace, whose keys are prefixed with its own ID.
+func (s *RedisStore) Namespace(name string) Store {
+	return NewRedisStore(getNamespaceStoreID(s.id, name), s.redisClient)
+}
+
+// CreateNamespace adds the namespace to the registry of the node, a hash with a field per namespace.
+func (s *RedisStore) CreateNamespace(ctx context.Context, namespace *Namespace) error {
+	encoded, err := encodeNamespace(namespace)
+	if err != nil {
+		return err
+	}
+	created, err := s.redisClient.HSetNX(ctx, getNamespacesID(s.id), namespace.Name, encoded).Result()
+	if err != nil {
+		return translateRedisError(err, failure.Messagef("failed to save namespace %s", namespace.Name))
+	}
+	if !created {
+		return failure.New(appError.ErrAlreadyExists, failure.Messagef("namespace %s already exists", namespace.Name))
 	}
 	return nil
 }
 
-func (s *RedisStore) ReadAllEncodedAST(id string) ([][]byte, error) {
-	jsonEncodedAllEncodedAST, err := s.redisClient.Get(getAstID(s.id, id)).Bytes()
+func (s *RedisStore) ReadNamespace(ctx context.Context, name string) (*Namespace, error) {
+	encoded, err := s.redisClient.HGet(ctx, getNamespacesID(s.id), name).Bytes()
+	if err == redis.Nil {
+		return nil, failure.New(appError.ErrNotFound, failure.Messagef("namespace %s not found", name))
+	}
 	if err != nil {
-		return nil, failure.Translate(err, appError.ErrStoreOperationFailed, failure.Messagef("failed to get all encoded AST"))
+		return nil, translateRedisError(err, failure.Messagef("failed to get namespace %s", name))
 	}
-	allEncodedAST, err := jsonDecodeAllEncodedAST(jsonEncodedAllEncodedAST)
+	return decodeNamespace(encoded)
+}
+
+func (s *RedisStore) ReadNamespaces(ctx context.Context) ([]*Namespace, error) {
+	fields, err := s.redisClient.HGetAll(ctx, getNamespacesID(s.id)).Result()
 	if err != nil {
-		return nil, err
+		return nil, translateRedisError(err, failure.Messagef("failed to get namespaces"))
+	}
+	namespaces := make([]*Namespace, 0, len(fields))
+	for _, encoded := range fields {
+		namespace, err := decodeNamespace([]byte(encoded))
+		if err != nil {
+			return nil, err
+		}
+		namespaces = append(namespaces, namespace)
 	}
-	return allEncodedAST, nil
+	sort.Slice(namespaces, func(i, j int) bool { return namespaces[i].Name < namespaces[j].Name })
+	return namespaces, nil
+}
+
+// DeleteNamespace deletes the keys of the namespace before removing it from the registry,
+// so that a namespace created again with the same name does not find them.
+func (s *RedisStore) DeleteNamespace(ctx context.Context, name string) error {
+	if _, err := s.ReadNamespace(ctx, name); err != nil {
+		return err
+	}
+	if err := s.Namespace(name).Reset(ctx); err != nil {
+		return err
+	}
+	if err := s.redisClient.HDel(ctx, getNamespacesID(s.id), name).Err(); err != nil {
+		return translateRedisError(err, failure.Messagef("failed to delete namespace %s", name))
+	}
+	return nil
 }
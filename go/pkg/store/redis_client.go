@@ -0,0 +1,93 @@
+package store
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+
+	"github.com/morikuni/failure/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/config"
+)
+
+// NewRedisClient creates the client of the configured mode: a single node, a master found through
+// the sentinels (which follows failovers) or a cluster.
+func NewRedisClient(cfg *config.RedisConfig) (redis.UniversalClient, error) {
+	tlsConfig, err := newRedisTLSConfig(&cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+	addrs := cfg.Addrs
+	if len(addrs) == 0 {
+		addrs = []string{cfg.Addr}
+	}
+
+	// go-redis authenticates as the ACL user, if any, when a connection is initialized and selects the DB afterwards
+	switch cfg.Mode {
+	case "", "standalone":
+		return redis.NewClient(&redis.Options{
+			Addr:      cfg.Addr,
+			Username:  cfg.Username,
+			Password:  cfg.Password,
+			DB:        cfg.DB,
+			PoolSize:  cfg.PoolSize,
+			TLSConfig: tlsConfig,
+			// The deadline of the context of a command bounds its reads and writes as well as the wait for a connection
+			ContextTimeoutEnabled: true,
+		}), nil
+	case "sentinel":
+		if cfg.MasterName == "" {
+			return nil, failure.New(appError.ErrConfigError, failure.Messagef("masterName is required in sentinel mode"))
+		}
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:            cfg.MasterName,
+			SentinelAddrs:         addrs,
+			Username:              cfg.Username,
+			Password:              cfg.Password,
+			DB:                    cfg.DB,
+			PoolSize:              cfg.PoolSize,
+			TLSConfig:             tlsConfig,
+			ContextTimeoutEnabled: true,
+		}), nil
+	case "cluster":
+		if cfg.DB != 0 {
+			return nil, failure.New(appError.ErrConfigError, failure.Messagef("db must be 0 in cluster mode"))
+		}
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:                 addrs,
+			Username:              cfg.Username,
+			Password:              cfg.Password,
+			PoolSize:              cfg.PoolSize,
+			TLSConfig:             tlsConfig,
+			ContextTimeoutEnabled: true,
+		}), nil
+	default:
+		return nil, failure.New(appError.ErrConfigError, failure.Messagef("invalid redis mode: %s", cfg.Mode))
+	}
+}
+
+func newRedisTLSConfig(cfg *config.RedisTLSConfig) (*tls.Config, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.CAPath != "" {
+		ca, err := os.ReadFile(cfg.CAPath)
+		if err != nil {
+			return nil, failure.Translate(err, appError.ErrConfigError, failure.Messagef("failed to read redis CA certificate"))
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, failure.New(appError.ErrConfigError, failure.Messagef("redis CA certificate is not PEM encoded"))
+		}
+	}
+	if cfg.CertPath != "" || cfg.KeyPath != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertPath, cfg.KeyPath)
+		if err != nil {
+			return nil, failure.Translate(err, appError.ErrConfigError, failure.Messagef("failed to load redis client certificate"))
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
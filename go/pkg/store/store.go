@@ -1,13 +1,71 @@
 package store
 
-import "github.com/shibukazu/open-ve/go/pkg/dsl"
+import (
+	"context"
+	"time"
 
+	"github.com/shibukazu/open-ve/go/pkg/dsl"
+)
+
+// Store keeps the compiled DSL of a node.
+// Reads of what does not exist fail with appError.ErrNotFound, and failures to reach the backend
+// (connection errors, timeouts or a canceled context) fail with appError.ErrUnavailable.
 type Store interface {
-	Reset() error
-	WriteSchema(*dsl.DSL) error
-	ReadSchema() (*dsl.DSL, error)
-	WriteVariables(string, []dsl.Variable) error
-	ReadVariables(string) ([]dsl.Variable, error)
-	WriteAllEncodedAST(string, [][]byte) error
-	ReadAllEncodedAST(string) ([][]byte, error)
+	Reset(context.Context) error
+	// WriteSchema replaces the schema and its file descriptor set, which is kept apart so that checks read it alone.
+	WriteSchema(context.Context, *dsl.DSL) error
+	ReadSchema(context.Context) (*dsl.DSL, error)
+	WriteCompiledValidation(context.Context, string, *CompiledValidation) error
+	ReadCompiledValidation(context.Context, string) (*CompiledValidation, error)
+	// WriteDataset replaces the entries of the dataset. The members of a set are keys with empty values.
+	WriteDataset(context.Context, string, map[string]string) error
+	// ReadDataset returns an empty map if the dataset has no entries.
+	ReadDataset(context.Context, string) (map[string]string, error)
+	LookupDataset(ctx context.Context, name string, key string) (string, bool, error)
+	// ReadFileDescriptorSet returns nil if no file descriptor set is registered.
+	ReadFileDescriptorSet(context.Context) ([]byte, error)
+	// Notify changes the version of the node after its DSL or datasets changed, and publishes it to the subscribers
+	// including those of other processes sharing the store.
+	Notify(context.Context) error
+	// Subscribe returns a channel receiving the versions published by Notify until the context is done,
+	// when the channel is closed. Versions published while the subscriber is disconnected are lost.
+	Subscribe(context.Context) (<-chan string, error)
+	// ReadVersion returns the current version of the node, or an empty string if it has never been notified.
+	ReadVersion(context.Context) (string, error)
+}
+
+// Transactor is implemented by stores that can apply several writes atomically.
+type Transactor interface {
+	// Transaction runs fn with a store whose writes are committed together when fn returns nil,
+	// and discarded otherwise.
+	Transaction(ctx context.Context, fn func(Store) error) error
+}
+
+// CompiledValidation is a validation with the checked ASTs of its expressions, stored as a single record
+// so that a check reads it at once.
+type CompiledValidation struct {
+	Variables     []dsl.Variable
+	AllEncodedAST [][]byte
+	Computed      []EncodedComputedVariable
+	Profiles      []EncodedProfile
+	// CompileTime is when the expressions were checked, zero for the validations migrated from the legacy format.
+	CompileTime time.Time
+	// CELVersion is the version of cel-go the expressions were checked with, empty if unknown.
+	CELVersion string
+}
+
+// EncodedComputedVariable is a computed variable with its checked AST encoded.
+type EncodedComputedVariable struct {
+	Name       string `json:"name"`
+	EncodedAST []byte `json:"encodedAST"`
+}
+
+// EncodedProfile is a profile with the checked ASTs of its rules encoded.
+type EncodedProfile struct {
+	Name string `json:"name"`
+	// AllEncodedAST are the rules added by the profile.
+	AllEncodedAST [][]byte `json:"allEncodedAST"`
+	// Exclude are the indexes of the rules of the validation not evaluated.
+	Exclude  []int    `json:"exclude"`
+	Optional []string `json:"optional"`
 }
@@ -1,34 +1,23 @@
 package store
 
-import (
-	"bytes"
-	"encoding/json"
-
-	"github.com/morikuni/failure/v2"
-	"github.com/shibukazu/open-ve/go/pkg/appError"
-)
+func getCompiledValidationID(nodeId string, id string) string {
+	return nodeId + ":validation:" + id
+}
 
-func getVariablesID(nodeId string, id string) string {
-	return nodeId + ":variables:" + id
+func getDatasetID(nodeId string, name string) string {
+	return nodeId + ":dataset:" + name
 }
 
-func getAstID(nodeId string, id string) string {
-	return nodeId + ":ast:" + id
+func getFileDescriptorSetID(nodeId string) string {
+	return nodeId + ":descriptors"
 }
 
-func jsonEncodeAllEncodedAST(allEncodedAST [][]byte) ([]byte, error) {
-	var buf bytes.Buffer
-	enc := json.NewEncoder(&buf)
-	if err := enc.Encode(allEncodedAST); err != nil {
-		return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("Failed to encode AST: %v", err))
-	}
-	return buf.Bytes(), nil
+// getNamespaceStoreID is the ID the store of a namespace is kept under, apart from the keys of the node.
+func getNamespaceStoreID(nodeId string, name string) string {
+	return nodeId + "@" + name
 }
 
-func jsonDecodeAllEncodedAST(jsonEncodedAllEncodedAST []byte) ([][]byte, error) {
-	var allEncodedAST [][]byte
-	if err := json.Unmarshal(jsonEncodedAllEncodedAST, &allEncodedAST); err != nil {
-		return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("Failed to decode encoded AST: %v", err))
-	}
-	return allEncodedAST, nil
+// getNamespacesID is the registry of the namespaces of the node. Names of namespaces can't contain #.
+func getNamespacesID(nodeId string) string {
+	return nodeId + "#namespaces"
 }
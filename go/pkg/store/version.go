@@ -0,0 +1,54 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"sort"
+
+	"github.com/morikuni/failure/v2"
+	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/dsl"
+	"google.golang.org/protobuf/proto"
+)
+
+// SchemaVersion hashes the deterministic encoding of the schema with the file descriptor set and the hashes of
+// the contents of its datasets, so that every node holding the same DSL reports the same version
+// and registering a dataset changes it. The Hash of each dataset must be set.
+func SchemaVersion(schema *dsl.DSL) (string, error) {
+	s, err := schemaToProto(schema)
+	if err != nil {
+		return "", err
+	}
+	s.FormatVersion = 0
+	s.Version = ""
+	encoded, err := proto.MarshalOptions{Deterministic: true}.Marshal(s)
+	if err != nil {
+		return "", failure.Translate(err, appError.ErrServerError, failure.Messagef("failed to encode schema"))
+	}
+	h := sha256.New()
+	writeLengthPrefixed(h, encoded)
+	writeLengthPrefixed(h, schema.FileDescriptorSet)
+	return hex.EncodeToString(h.Sum(nil)[:16]), nil
+}
+
+// DatasetHash hashes the entries of a dataset in the order of their keys.
+func DatasetHash(entries map[string]string) string {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, key := range keys {
+		writeLengthPrefixed(h, []byte(key))
+		writeLengthPrefixed(h, []byte(entries[key]))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func writeLengthPrefixed(h hash.Hash, data []byte) {
+	h.Write(binary.AppendUvarint(nil, uint64(len(data))))
+	h.Write(data)
+}
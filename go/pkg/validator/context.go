@@ -0,0 +1,41 @@
+package validator
+
+import (
+	"context"
+	"time"
+
+	"github.com/shibukazu/open-ve/go/pkg/dsl/util"
+)
+
+// RequestContext is injected into every evaluation as the openve.now and openve.request variables.
+type RequestContext struct {
+	Principal string
+	// Metadata are the selected gRPC metadata (or HTTP headers) of the request.
+	Metadata map[string]string
+	Time     time.Time
+}
+
+type requestContextKey struct{}
+
+func WithRequestContext(ctx context.Context, requestContext *RequestContext) context.Context {
+	return context.WithValue(ctx, requestContextKey{}, requestContext)
+}
+
+func requestContextFrom(ctx context.Context) *RequestContext {
+	if requestContext, ok := ctx.Value(requestContextKey{}).(*RequestContext); ok {
+		return requestContext
+	}
+	return &RequestContext{Metadata: map[string]string{}, Time: time.Now()}
+}
+
+// withContextVariables returns the variables including the context variables.
+func withContextVariables(variables map[string]interface{}, requestContext *RequestContext) map[string]interface{} {
+	withContext := make(map[string]interface{}, len(variables)+2)
+	for name, value := range variables {
+		withContext[name] = value
+	}
+	for name, value := range util.ContextVariables(requestContext.Principal, requestContext.Metadata, requestContext.Time) {
+		withContext[name] = value
+	}
+	return withContext
+}
@@ -1,100 +1,638 @@
 package validator
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"sort"
 	"strings"
 
 	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+	"github.com/google/cel-go/parser"
 	"github.com/morikuni/failure/v2"
 	"github.com/shibukazu/open-ve/go/pkg/appError"
+	"github.com/shibukazu/open-ve/go/pkg/dsl"
+	"github.com/shibukazu/open-ve/go/pkg/dsl/binding"
+	"github.com/shibukazu/open-ve/go/pkg/dsl/reader"
 	"github.com/shibukazu/open-ve/go/pkg/dsl/util"
 	"github.com/shibukazu/open-ve/go/pkg/store"
 	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/anypb"
 )
 
 type Validator struct {
-	store  store.Store
-	logger *slog.Logger
+	store store.Store
+	// dslReader caches the parsed file descriptor set, so that it is not parsed on every check.
+	dslReader *reader.DSLReader
+	logger    *slog.Logger
 }
 
-func NewValidator(logger *slog.Logger, store store.Store) *Validator {
-	return &Validator{logger: logger, store: store}
+// Result is the result of validating variables against a validation ID.
+type Result struct {
+	IsValid bool
+	Message string
+	// Errors has an entry per failed boolean rule and per error reported by the other rules.
+	Errors       []RuleError
+	Explanations []Explanation
+	// Computed is the value of each computed variable. It is only set in explain mode.
+	Computed map[string]string
 }
 
-func (v *Validator) Validate(id string, variables map[string]interface{}) (bool, string, error) {
-	dslVariables, err := v.store.ReadVariables(id)
+type RuleError struct {
+	Cel        string
+	Message    string
+	Attributes map[string]string
+}
+
+type Explanation struct {
+	Cel         string
+	IsValid     bool
+	Variables   map[string]string
+	Expressions []EvaluatedExpression
+}
+
+type EvaluatedExpression struct {
+	Expression string
+	Value      string
+}
+
+type RecordResult struct {
+	Index   int
+	IsValid bool
+	Message string
+	Errors  []RuleError
+	Err     error
+}
+
+// DocumentFailure is a failed rule or a variable that could not be extracted from the document.
+type DocumentFailure struct {
+	Cel     string
+	Paths   []string
+	Message string
+}
+
+// ruleSet is a compiled validation.
+type ruleSet struct {
+	rules     []rule
+	computed  []computedVariable
+	variables []dsl.Variable
+	files     *protoregistry.Files
+	// optional are the variables the profile allows to omit.
+	optional map[string]bool
+}
+
+// rule is a compiled rule. variables are the names of the variables it depends on,
+// including those the computed variables it refers to depend on.
+type rule struct {
+	ast       *cel.Ast
+	program   cel.Program
+	variables []string
+}
+
+type computedVariable struct {
+	name      string
+	program   cel.Program
+	variables []string
+}
+
+type ruleResult struct {
+	index       int
+	isValid     bool
+	cel         string
+	errors      []util.ResultError
+	explanation *Explanation
+}
+
+func NewValidator(logger *slog.Logger, store store.Store, dslReader *reader.DSLReader) *Validator {
+	return &Validator{logger: logger, store: store, dslReader: dslReader}
+}
+
+// Validate validates the variables against the rules of the validation ID adjusted by the profile.
+// The rules of the validation are used as they are if profile is empty.
+func (v *Validator) Validate(ctx context.Context, id string, profile string, variables map[string]interface{}) (*Result, error) {
+	rs, err := v.loadRules(ctx, id, profile, false)
 	if err != nil {
-		return false, "", err
+		return nil, err
 	}
-	celVariables, err := util.DSLVariablesToCELVariables(dslVariables)
+	variables, err = rs.resolveVariables(variables)
 	if err != nil {
-		return false, "", err
+		return nil, err
 	}
+	return rs.evaluate(withContextVariables(variables, requestContextFrom(ctx)), false)
+}
 
-	env, err := cel.NewEnv(celVariables...)
+// ValidateDocument extracts the variables from the document by their declared paths and validates them.
+// Rules referring to a variable that could not be extracted are not evaluated.
+func (v *Validator) ValidateDocument(ctx context.Context, id string, document interface{}) (bool, string, []DocumentFailure, error) {
+	rs, err := v.loadRules(ctx, id, "", false)
 	if err != nil {
-		return false, "", failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to create CEL environment: %v", err))
+		return false, "", nil, err
 	}
+	rules, dslVariables := rs.rules, rs.variables
 
-	allEncodedAST, err := v.store.ReadAllEncodedAST(id)
+	failures := make([]DocumentFailure, 0)
+	variables := make(map[string]interface{}, len(dslVariables))
+	variablePaths := make(map[string]string, len(dslVariables))
+	for _, dslVariable := range dslVariables {
+		path, err := binding.VariablePath(&dslVariable)
+		if err != nil {
+			return false, "", nil, err
+		}
+		variablePaths[dslVariable.Name] = path.String()
+		value, found := path.Lookup(document)
+		if !found {
+			failures = append(failures, DocumentFailure{Paths: []string{path.String()}, Message: fmt.Sprintf("%s: value not found", path)})
+			continue
+		}
+		var converted interface{}
+		if util.IsPrimitiveType(dslVariable.Type) {
+			converted, err = binding.Convert(value, dslVariable.Type)
+		} else {
+			var md protoreflect.MessageDescriptor
+			md, err = util.FindMessageDescriptor(rs.files, dslVariable.Type)
+			if err != nil {
+				return false, "", nil, err
+			}
+			converted, err = binding.ConvertMessage(value, md)
+		}
+		if err != nil {
+			failures = append(failures, DocumentFailure{Paths: []string{path.String()}, Message: fmt.Sprintf("%s: %s", path, failure.MessageOf(err))})
+			continue
+		}
+		variables[dslVariable.Name] = converted
+	}
+
+	evaluableRules := make([]rule, 0, len(rules))
+	for _, r := range rules {
+		evaluable := true
+		for _, name := range r.variables {
+			if _, ok := variables[name]; !ok {
+				evaluable = false
+				break
+			}
+		}
+		if evaluable {
+			evaluableRules = append(evaluableRules, r)
+		}
+	}
+	variables, err = rs.computeVariables(withContextVariables(variables, requestContextFrom(ctx)))
 	if err != nil {
-		return false, "", err
+		return false, "", nil, err
+	}
+	results, err := evaluateRules(evaluableRules, variables, false)
+	if err != nil {
+		return false, "", nil, err
+	}
+	for _, result := range results {
+		if result.isValid {
+			continue
+		}
+		paths := make([]string, 0)
+		for _, name := range evaluableRules[result.index].variables {
+			paths = append(paths, variablePaths[name])
+		}
+		if len(result.errors) == 0 {
+			failures = append(failures, DocumentFailure{Cel: result.cel, Paths: paths, Message: fmt.Sprintf("failed validation: %s", result.cel)})
+			continue
+		}
+		for _, resultError := range result.errors {
+			failures = append(failures, DocumentFailure{Cel: result.cel, Paths: paths, Message: resultError.Message})
+		}
 	}
 
-	successCh := make(chan bool, len(allEncodedAST))
-	errorCh := make(chan error, len(allEncodedAST))
-	failedCELCh := make(chan string, len(allEncodedAST))
-	// execute all validation asynchronously
+	if len(failures) == 0 {
+		return true, "", failures, nil
+	}
+	messages := make([]string, len(failures))
+	for i, f := range failures {
+		messages[i] = f.Message
+	}
+	return false, strings.Join(messages, ", "), failures, nil
+}
+
+// Explain validates like Validate but evaluates every rule exhaustively
+// and returns the value of each sub-expression and variable per rule.
+func (v *Validator) Explain(ctx context.Context, id string, profile string, variables map[string]interface{}) (*Result, error) {
+	rs, err := v.loadRules(ctx, id, profile, true)
+	if err != nil {
+		return nil, err
+	}
+	variables, err = rs.resolveVariables(variables)
+	if err != nil {
+		return nil, err
+	}
+	return rs.evaluate(withContextVariables(variables, requestContextFrom(ctx)), true)
+}
+
+// ValidateBulk validates many records against one validation ID.
+// The schema is resolved once, and a record that fails to evaluate does not abort the others.
+func (v *Validator) ValidateBulk(ctx context.Context, id string, records []map[string]interface{}) ([]RecordResult, error) {
+	rs, err := v.loadRules(ctx, id, "", false)
+	if err != nil {
+		return nil, err
+	}
+	// Every record sees the same request context
+	requestContext := requestContextFrom(ctx)
+	results := make([]RecordResult, len(records))
+	for idx, variables := range records {
+		variables, err := rs.resolveVariables(variables)
+		if err != nil {
+			results[idx] = RecordResult{Index: idx, IsValid: false, Err: err}
+			continue
+		}
+		result, err := rs.evaluate(withContextVariables(variables, requestContext), false)
+		if err != nil {
+			results[idx] = RecordResult{Index: idx, IsValid: false, Err: err}
+			continue
+		}
+		results[idx] = RecordResult{Index: idx, IsValid: result.IsValid, Message: result.Message, Errors: result.Errors}
+	}
+	return results, nil
+}
+
+func (v *Validator) loadRules(ctx context.Context, id string, profile string, explain bool) (*ruleSet, error) {
+	compiled, err := v.store.ReadCompiledValidation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	dslVariables := compiled.Variables
+	files, err := v.dslReader.ReadFileDescriptorSet(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	env, err := util.NewCELEnv(dslVariables, files, &storeDatasets{ctx: ctx, store: v.store})
+	if err != nil {
+		return nil, err
+	}
+
+	allEncodedAST := compiled.AllEncodedAST
+
+	programOpts := []cel.ProgramOption{}
+	if explain {
+		programOpts = append(programOpts, cel.EvalOptions(cel.OptExhaustiveEval))
+	}
+
+	encodedComputedVariables := compiled.Computed
+
+	// Variables each declared name depends on. A computed variable depends on the variables it refers to transitively.
+	dependencies := make(map[string][]string, len(dslVariables)+len(encodedComputedVariables))
+	for _, dslVariable := range dslVariables {
+		dependencies[dslVariable.Name] = []string{dslVariable.Name}
+	}
+
+	computed := make([]computedVariable, 0, len(encodedComputedVariables))
+	for _, encodedComputedVariable := range encodedComputedVariables {
+		ast, err := decodeAST(encodedComputedVariable.EncodedAST)
+		if err != nil {
+			return nil, err
+		}
+		prg, err := env.Program(ast)
+		if err != nil {
+			return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to create cel program of computed variable %s", encodedComputedVariable.Name))
+		}
+		variables := util.ReferencedVariables(ast, dependencies)
+		computed = append(computed, computedVariable{name: encodedComputedVariable.Name, program: prg, variables: variables})
+		dependencies[encodedComputedVariable.Name] = variables
+		env, err = util.ExtendComputedVariable(env, encodedComputedVariable.Name, ast)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	optional := make(map[string]bool)
+	if profile != "" {
+		encodedProfile, err := findProfile(compiled, id, profile)
+		if err != nil {
+			return nil, err
+		}
+		excluded := make(map[int]bool, len(encodedProfile.Exclude))
+		for _, index := range encodedProfile.Exclude {
+			excluded[index] = true
+		}
+		profileEncodedAST := make([][]byte, 0, len(allEncodedAST)+len(encodedProfile.AllEncodedAST))
+		for index, encodedAST := range allEncodedAST {
+			if !excluded[index] {
+				profileEncodedAST = append(profileEncodedAST, encodedAST)
+			}
+		}
+		allEncodedAST = append(profileEncodedAST, encodedProfile.AllEncodedAST...)
+		for _, name := range encodedProfile.Optional {
+			optional[name] = true
+		}
+	}
+
+	rules := make([]rule, 0, len(allEncodedAST))
 	for _, encodedAST := range allEncodedAST {
-		go func(encodedAST []byte) {
-			var expr exprpb.CheckedExpr
-			if err = proto.Unmarshal(encodedAST, &expr); err != nil {
-				errorCh <- failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to unmarshal encoded AST"))
-				return
+		ast, err := decodeAST(encodedAST)
+		if err != nil {
+			return nil, err
+		}
+		prg, err := env.Program(ast, programOpts...)
+		if err != nil {
+			return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to create cel program"))
+		}
+		rules = append(rules, rule{ast: ast, program: prg, variables: util.ReferencedVariables(ast, dependencies)})
+	}
+	return &ruleSet{rules: rules, computed: computed, variables: dslVariables, files: files, optional: optional}, nil
+}
+
+func findProfile(compiled *store.CompiledValidation, id string, name string) (*store.EncodedProfile, error) {
+	for _, profile := range compiled.Profiles {
+		if profile.Name == name {
+			return &profile, nil
+		}
+	}
+	return nil, failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("profile %s is not defined in validation %s", name, id))
+}
+
+// storeDatasets looks up the datasets in the store on each call
+// so that large datasets are not loaded per validation.
+// It is created per request, so the lookups are made with the context of the request.
+type storeDatasets struct {
+	ctx   context.Context
+	store store.Store
+}
+
+func (d *storeDatasets) Lookup(name string, key string) (string, bool, error) {
+	return d.store.LookupDataset(d.ctx, name, key)
+}
+
+func decodeAST(encodedAST []byte) (*cel.Ast, error) {
+	var expr exprpb.CheckedExpr
+	if err := proto.Unmarshal(encodedAST, &expr); err != nil {
+		return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to unmarshal encoded AST"))
+	}
+	return cel.CheckedExprToAst(&expr), nil
+}
+
+// computeVariables evaluates the computed variables once and returns the variables including them.
+// A computed variable depending on a missing variable is left undefined.
+func (rs *ruleSet) computeVariables(variables map[string]interface{}) (map[string]interface{}, error) {
+	if len(rs.computed) == 0 {
+		return variables, nil
+	}
+	computed := make(map[string]interface{}, len(variables)+len(rs.computed))
+	for name, value := range variables {
+		computed[name] = value
+	}
+	for _, c := range rs.computed {
+		evaluable := true
+		for _, name := range c.variables {
+			if _, ok := computed[name]; !ok {
+				evaluable = false
+				break
 			}
+		}
+		if !evaluable {
+			continue
+		}
+		val, _, err := c.program.Eval(computed)
+		if err != nil {
+			return nil, failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to evaluate computed variable %s", c.name))
+		}
+		computed[c.name] = val
+	}
+	return computed, nil
+}
 
-			ast := cel.CheckedExprToAst(&expr)
-			prg, err := env.Program(ast)
+// resolveVariables parses the decimal variables given as strings and unpacks the variables given as
+// google.protobuf.Any into messages of the registered descriptors.
+// The messages are built from the same descriptors as the CEL environment so that CEL can navigate their fields.
+func (rs *ruleSet) resolveVariables(variables map[string]interface{}) (map[string]interface{}, error) {
+	// resolved is copied from variables on the first change
+	var resolved map[string]interface{}
+	set := func(name string, value interface{}) {
+		if resolved == nil {
+			resolved = make(map[string]interface{}, len(variables))
+			for k, v := range variables {
+				resolved[k] = v
+			}
+		}
+		resolved[name] = value
+	}
+	for name, value := range variables {
+		variableType := ""
+		for _, dslVariable := range rs.variables {
+			if dslVariable.Name == name {
+				variableType = dslVariable.Type
+				break
+			}
+		}
+		if variableType == util.TypeDecimal {
+			d, err := util.ToDecimal(value)
 			if err != nil {
-				errorCh <- failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to create cel program"))
-				return
+				return nil, failure.Wrap(err, failure.Messagef("invalid variable %s", name))
+			}
+			set(name, d)
+			continue
+		}
+		anyValue, ok := value.(*anypb.Any)
+		if !ok {
+			continue
+		}
+		if variableType == "" || util.IsPrimitiveType(variableType) {
+			return nil, failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("unsupported type of variable %s: %s", name, anyValue.TypeUrl))
+		}
+		if string(anyValue.MessageName()) != variableType {
+			return nil, failure.New(appError.ErrRequestParameterInvalid, failure.Messagef("variable %s must be %s but got %s", name, variableType, anyValue.MessageName()))
+		}
+		md, err := util.FindMessageDescriptor(rs.files, variableType)
+		if err != nil {
+			return nil, err
+		}
+		msg := dynamicpb.NewMessage(md)
+		if err := proto.Unmarshal(anyValue.Value, msg); err != nil {
+			return nil, failure.Translate(err, appError.ErrRequestParameterInvalid, failure.Messagef("failed to unmarshal variable %s as %s", name, variableType))
+		}
+		set(name, msg)
+	}
+	if resolved == nil {
+		return variables, nil
+	}
+	return resolved, nil
+}
+
+func (rs *ruleSet) evaluate(variables map[string]interface{}, explain bool) (*Result, error) {
+	variables, err := rs.computeVariables(variables)
+	if err != nil {
+		return nil, err
+	}
+	results, err := evaluateRules(rs.evaluableRules(variables), variables, explain)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{IsValid: true, Errors: make([]RuleError, 0)}
+	if explain {
+		res.Computed = make(map[string]string, len(rs.computed))
+		for _, c := range rs.computed {
+			if val, ok := variables[c.name].(ref.Val); ok {
+				res.Computed[c.name] = formatValue(val)
+			}
+		}
+	}
+	// Failed boolean rules are reported by their CEL, and the other rules by their error messages
+	var failures []string
+	for _, result := range results {
+		if !result.isValid {
+			res.IsValid = false
+			if len(result.errors) == 0 {
+				failures = append(failures, result.cel)
+				res.Errors = append(res.Errors, RuleError{Cel: result.cel, Message: fmt.Sprintf("failed validation: %s", result.cel)})
+			}
+			for _, resultError := range result.errors {
+				failures = append(failures, resultError.Message)
+				res.Errors = append(res.Errors, RuleError{Cel: result.cel, Message: resultError.Message, Attributes: resultError.Attributes})
+			}
+		}
+		if result.explanation != nil {
+			res.Explanations = append(res.Explanations, *result.explanation)
+		}
+	}
+	if len(failures) != 0 {
+		res.Message = fmt.Sprintf("failed validations: %s", strings.Join(failures, ", "))
+	}
+	return res, nil
+}
+
+// evaluableRules returns the rules except those referring to an omitted optional variable.
+func (rs *ruleSet) evaluableRules(variables map[string]interface{}) []rule {
+	if len(rs.optional) == 0 {
+		return rs.rules
+	}
+	rules := make([]rule, 0, len(rs.rules))
+	for _, r := range rs.rules {
+		evaluable := true
+		for _, name := range r.variables {
+			if _, ok := variables[name]; !ok && rs.optional[name] {
+				evaluable = false
+				break
 			}
-			res, _, err := prg.Eval(variables)
+		}
+		if evaluable {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// evaluateRules returns the result of each rule in the order of rules.
+func evaluateRules(rules []rule, variables map[string]interface{}, explain bool) ([]ruleResult, error) {
+	resultCh := make(chan ruleResult, len(rules))
+	errorCh := make(chan error, len(rules))
+	// execute all validation asynchronously
+	for idx, r := range rules {
+		go func(idx int, r rule) {
+			res, details, err := r.program.Eval(variables)
 			if err != nil {
 				errorCh <- failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to evaluate cel program"))
 				return
 			}
 
-			if !res.Value().(bool) {
-				failedCEL, err := cel.AstToString(ast)
+			isValid, resultErrors, err := util.ParseResult(res)
+			if err != nil {
+				errorCh <- err
+				return
+			}
+			result := ruleResult{index: idx, isValid: isValid, errors: resultErrors}
+			if !result.isValid || explain {
+				result.cel, err = cel.AstToString(r.ast)
 				if err != nil {
 					errorCh <- failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to convert AST to string"))
 					return
 				}
-				failedCELCh <- failedCEL
-				return
 			}
-			successCh <- true
-		}(encodedAST)
+			if explain {
+				explanation, err := explainRule(r.ast, details.State())
+				if err != nil {
+					errorCh <- err
+					return
+				}
+				explanation.Cel = result.cel
+				explanation.IsValid = result.isValid
+				result.explanation = explanation
+			}
+			resultCh <- result
+		}(idx, r)
 	}
 
-	result := true
-	message := ""
-	var failedCELs []string
-	for i := 0; i < len(allEncodedAST); i++ {
+	results := make([]ruleResult, 0, len(rules))
+	for i := 0; i < len(rules); i++ {
 		select {
 		case err := <-errorCh:
-			return false, "", err
-		case failedCEL := <-failedCELCh:
-			result = false
-			failedCELs = append(failedCELs, failedCEL)
-		case <-successCh:
+			return nil, err
+		case result := <-resultCh:
+			results = append(results, result)
 		}
 	}
-	if len(failedCELs) != 0 {
-		message = fmt.Sprintf("failed validations: %s", strings.Join(failedCELs, ", "))
+	sort.Slice(results, func(i, j int) bool { return results[i].index < results[j].index })
+	return results, nil
+}
+
+// explainRule walks the checked AST in pre-order and pairs each evaluated
+// sub-expression with its value. Comprehensions are reported as a whole
+// since their loop internals are not meaningful to users.
+func explainRule(ast *cel.Ast, state interpreter.EvalState) (*Explanation, error) {
+	explanation := &Explanation{
+		Variables:   make(map[string]string),
+		Expressions: make([]EvaluatedExpression, 0),
+	}
+	nativeAST := ast.NativeRep()
+	sourceInfo := nativeAST.SourceInfo()
+
+	var walk func(expr celast.NavigableExpr) error
+	walk = func(expr celast.NavigableExpr) error {
+		if expr.Kind() == celast.LiteralKind {
+			return nil
+		}
+		if val, found := state.Value(expr.ID()); found {
+			if expr.Kind() == celast.IdentKind {
+				explanation.Variables[expr.AsIdent()] = formatValue(val)
+			} else {
+				unparsed, err := parser.Unparse(expr, sourceInfo)
+				if err != nil {
+					return failure.Translate(err, appError.ErrDSLSyntaxError, failure.Messagef("failed to convert expression to string"))
+				}
+				explanation.Expressions = append(explanation.Expressions, EvaluatedExpression{
+					Expression: unparsed,
+					Value:      formatValue(val),
+				})
+			}
+		}
+		if expr.Kind() == celast.ComprehensionKind {
+			return nil
+		}
+		for _, child := range expr.Children() {
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(celast.NavigateAST(nativeAST)); err != nil {
+		return nil, err
+	}
+	return explanation, nil
+}
+
+func formatValue(val ref.Val) string {
+	switch val := val.(type) {
+	case *types.Err:
+		return fmt.Sprintf("error: %s", val.Error())
+	case types.String:
+		return fmt.Sprintf("%q", string(val))
+	case types.Bytes:
+		return fmt.Sprintf("b%q", []byte(val))
+	default:
+		return fmt.Sprintf("%v", val.Value())
 	}
-	return result, message, nil
 }
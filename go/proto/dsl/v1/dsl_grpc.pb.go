@@ -19,8 +19,12 @@ import (
 const _ = grpc.SupportPackageIsVersion8
 
 const (
-	DSLService_Register_FullMethodName = "/dsl.v1.DSLService/Register"
-	DSLService_Read_FullMethodName     = "/dsl.v1.DSLService/Read"
+	DSLService_Register_FullMethodName        = "/dsl.v1.DSLService/Register"
+	DSLService_Read_FullMethodName            = "/dsl.v1.DSLService/Read"
+	DSLService_Watch_FullMethodName           = "/dsl.v1.DSLService/Watch"
+	DSLService_ReadBundle_FullMethodName      = "/dsl.v1.DSLService/ReadBundle"
+	DSLService_RegisterDataset_FullMethodName = "/dsl.v1.DSLService/RegisterDataset"
+	DSLService_ReadDataset_FullMethodName     = "/dsl.v1.DSLService/ReadDataset"
 )
 
 // DSLServiceClient is the client API for DSLService service.
@@ -29,6 +33,14 @@ const (
 type DSLServiceClient interface {
 	Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error)
 	Read(ctx context.Context, in *ReadRequest, opts ...grpc.CallOption) (*ReadResponse, error)
+	// Sends the schema whenever a DSL with a different version is registered.
+	// Over HTTP, it is served as Server-Sent Events at GET /v1/dsl/watch.
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (DSLService_WatchClient, error)
+	// Returns the compiled rules for evaluation on clients.
+	ReadBundle(ctx context.Context, in *ReadBundleRequest, opts ...grpc.CallOption) (*ReadBundleResponse, error)
+	// Replaces the contents of a dataset declared in the registered DSL.
+	RegisterDataset(ctx context.Context, in *RegisterDatasetRequest, opts ...grpc.CallOption) (*RegisterDatasetResponse, error)
+	ReadDataset(ctx context.Context, in *ReadDatasetRequest, opts ...grpc.CallOption) (*ReadDatasetResponse, error)
 }
 
 type dSLServiceClient struct {
@@ -59,12 +71,83 @@ func (c *dSLServiceClient) Read(ctx context.Context, in *ReadRequest, opts ...gr
 	return out, nil
 }
 
+func (c *dSLServiceClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (DSLService_WatchClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DSLService_ServiceDesc.Streams[0], DSLService_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dSLServiceWatchClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DSLService_WatchClient interface {
+	Recv() (*WatchResponse, error)
+	grpc.ClientStream
+}
+
+type dSLServiceWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *dSLServiceWatchClient) Recv() (*WatchResponse, error) {
+	m := new(WatchResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dSLServiceClient) ReadBundle(ctx context.Context, in *ReadBundleRequest, opts ...grpc.CallOption) (*ReadBundleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReadBundleResponse)
+	err := c.cc.Invoke(ctx, DSLService_ReadBundle_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dSLServiceClient) RegisterDataset(ctx context.Context, in *RegisterDatasetRequest, opts ...grpc.CallOption) (*RegisterDatasetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisterDatasetResponse)
+	err := c.cc.Invoke(ctx, DSLService_RegisterDataset_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dSLServiceClient) ReadDataset(ctx context.Context, in *ReadDatasetRequest, opts ...grpc.CallOption) (*ReadDatasetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReadDatasetResponse)
+	err := c.cc.Invoke(ctx, DSLService_ReadDataset_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DSLServiceServer is the server API for DSLService service.
 // All implementations must embed UnimplementedDSLServiceServer
 // for forward compatibility
 type DSLServiceServer interface {
 	Register(context.Context, *RegisterRequest) (*RegisterResponse, error)
 	Read(context.Context, *ReadRequest) (*ReadResponse, error)
+	// Sends the schema whenever a DSL with a different version is registered.
+	// Over HTTP, it is served as Server-Sent Events at GET /v1/dsl/watch.
+	Watch(*WatchRequest, DSLService_WatchServer) error
+	// Returns the compiled rules for evaluation on clients.
+	ReadBundle(context.Context, *ReadBundleRequest) (*ReadBundleResponse, error)
+	// Replaces the contents of a dataset declared in the registered DSL.
+	RegisterDataset(context.Context, *RegisterDatasetRequest) (*RegisterDatasetResponse, error)
+	ReadDataset(context.Context, *ReadDatasetRequest) (*ReadDatasetResponse, error)
 	mustEmbedUnimplementedDSLServiceServer()
 }
 
@@ -78,6 +161,18 @@ func (UnimplementedDSLServiceServer) Register(context.Context, *RegisterRequest)
 func (UnimplementedDSLServiceServer) Read(context.Context, *ReadRequest) (*ReadResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Read not implemented")
 }
+func (UnimplementedDSLServiceServer) Watch(*WatchRequest, DSLService_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedDSLServiceServer) ReadBundle(context.Context, *ReadBundleRequest) (*ReadBundleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReadBundle not implemented")
+}
+func (UnimplementedDSLServiceServer) RegisterDataset(context.Context, *RegisterDatasetRequest) (*RegisterDatasetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterDataset not implemented")
+}
+func (UnimplementedDSLServiceServer) ReadDataset(context.Context, *ReadDatasetRequest) (*ReadDatasetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReadDataset not implemented")
+}
 func (UnimplementedDSLServiceServer) mustEmbedUnimplementedDSLServiceServer() {}
 
 // UnsafeDSLServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -127,6 +222,81 @@ func _DSLService_Read_Handler(srv interface{}, ctx context.Context, dec func(int
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DSLService_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DSLServiceServer).Watch(m, &dSLServiceWatchServer{ServerStream: stream})
+}
+
+type DSLService_WatchServer interface {
+	Send(*WatchResponse) error
+	grpc.ServerStream
+}
+
+type dSLServiceWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *dSLServiceWatchServer) Send(m *WatchResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _DSLService_ReadBundle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadBundleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DSLServiceServer).ReadBundle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DSLService_ReadBundle_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DSLServiceServer).ReadBundle(ctx, req.(*ReadBundleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DSLService_RegisterDataset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterDatasetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DSLServiceServer).RegisterDataset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DSLService_RegisterDataset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DSLServiceServer).RegisterDataset(ctx, req.(*RegisterDatasetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DSLService_ReadDataset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadDatasetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DSLServiceServer).ReadDataset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DSLService_ReadDataset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DSLServiceServer).ReadDataset(ctx, req.(*ReadDatasetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // DSLService_ServiceDesc is the grpc.ServiceDesc for DSLService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -142,7 +312,25 @@ var DSLService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Read",
 			Handler:    _DSLService_Read_Handler,
 		},
+		{
+			MethodName: "ReadBundle",
+			Handler:    _DSLService_ReadBundle_Handler,
+		},
+		{
+			MethodName: "RegisterDataset",
+			Handler:    _DSLService_RegisterDataset_Handler,
+		},
+		{
+			MethodName: "ReadDataset",
+			Handler:    _DSLService_ReadDataset_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _DSLService_Watch_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/dsl/v1/dsl.proto",
 }
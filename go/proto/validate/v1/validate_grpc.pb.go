@@ -19,7 +19,10 @@ import (
 const _ = grpc.SupportPackageIsVersion8
 
 const (
-	ValidateService_Check_FullMethodName = "/validate.v1.ValidateService/Check"
+	ValidateService_Check_FullMethodName         = "/validate.v1.ValidateService/Check"
+	ValidateService_BulkCheck_FullMethodName     = "/validate.v1.ValidateService/BulkCheck"
+	ValidateService_CheckStream_FullMethodName   = "/validate.v1.ValidateService/CheckStream"
+	ValidateService_CheckDocument_FullMethodName = "/validate.v1.ValidateService/CheckDocument"
 )
 
 // ValidateServiceClient is the client API for ValidateService service.
@@ -27,6 +30,9 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ValidateServiceClient interface {
 	Check(ctx context.Context, in *CheckRequest, opts ...grpc.CallOption) (*CheckResponse, error)
+	BulkCheck(ctx context.Context, in *BulkCheckRequest, opts ...grpc.CallOption) (*BulkCheckResponse, error)
+	CheckStream(ctx context.Context, opts ...grpc.CallOption) (ValidateService_CheckStreamClient, error)
+	CheckDocument(ctx context.Context, in *CheckDocumentRequest, opts ...grpc.CallOption) (*CheckDocumentResponse, error)
 }
 
 type validateServiceClient struct {
@@ -47,11 +53,66 @@ func (c *validateServiceClient) Check(ctx context.Context, in *CheckRequest, opt
 	return out, nil
 }
 
+func (c *validateServiceClient) BulkCheck(ctx context.Context, in *BulkCheckRequest, opts ...grpc.CallOption) (*BulkCheckResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BulkCheckResponse)
+	err := c.cc.Invoke(ctx, ValidateService_BulkCheck_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *validateServiceClient) CheckStream(ctx context.Context, opts ...grpc.CallOption) (ValidateService_CheckStreamClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ValidateService_ServiceDesc.Streams[0], ValidateService_CheckStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &validateServiceCheckStreamClient{ClientStream: stream}
+	return x, nil
+}
+
+type ValidateService_CheckStreamClient interface {
+	Send(*CheckStreamRequest) error
+	Recv() (*CheckStreamResponse, error)
+	grpc.ClientStream
+}
+
+type validateServiceCheckStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *validateServiceCheckStreamClient) Send(m *CheckStreamRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *validateServiceCheckStreamClient) Recv() (*CheckStreamResponse, error) {
+	m := new(CheckStreamResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *validateServiceClient) CheckDocument(ctx context.Context, in *CheckDocumentRequest, opts ...grpc.CallOption) (*CheckDocumentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckDocumentResponse)
+	err := c.cc.Invoke(ctx, ValidateService_CheckDocument_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ValidateServiceServer is the server API for ValidateService service.
 // All implementations must embed UnimplementedValidateServiceServer
 // for forward compatibility
 type ValidateServiceServer interface {
 	Check(context.Context, *CheckRequest) (*CheckResponse, error)
+	BulkCheck(context.Context, *BulkCheckRequest) (*BulkCheckResponse, error)
+	CheckStream(ValidateService_CheckStreamServer) error
+	CheckDocument(context.Context, *CheckDocumentRequest) (*CheckDocumentResponse, error)
 	mustEmbedUnimplementedValidateServiceServer()
 }
 
@@ -62,6 +123,15 @@ type UnimplementedValidateServiceServer struct {
 func (UnimplementedValidateServiceServer) Check(context.Context, *CheckRequest) (*CheckResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Check not implemented")
 }
+func (UnimplementedValidateServiceServer) BulkCheck(context.Context, *BulkCheckRequest) (*BulkCheckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BulkCheck not implemented")
+}
+func (UnimplementedValidateServiceServer) CheckStream(ValidateService_CheckStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method CheckStream not implemented")
+}
+func (UnimplementedValidateServiceServer) CheckDocument(context.Context, *CheckDocumentRequest) (*CheckDocumentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckDocument not implemented")
+}
 func (UnimplementedValidateServiceServer) mustEmbedUnimplementedValidateServiceServer() {}
 
 // UnsafeValidateServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -93,6 +163,68 @@ func _ValidateService_Check_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ValidateService_BulkCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BulkCheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ValidateServiceServer).BulkCheck(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ValidateService_BulkCheck_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ValidateServiceServer).BulkCheck(ctx, req.(*BulkCheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ValidateService_CheckStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ValidateServiceServer).CheckStream(&validateServiceCheckStreamServer{ServerStream: stream})
+}
+
+type ValidateService_CheckStreamServer interface {
+	Send(*CheckStreamResponse) error
+	Recv() (*CheckStreamRequest, error)
+	grpc.ServerStream
+}
+
+type validateServiceCheckStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *validateServiceCheckStreamServer) Send(m *CheckStreamResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *validateServiceCheckStreamServer) Recv() (*CheckStreamRequest, error) {
+	m := new(CheckStreamRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _ValidateService_CheckDocument_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckDocumentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ValidateServiceServer).CheckDocument(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ValidateService_CheckDocument_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ValidateServiceServer).CheckDocument(ctx, req.(*CheckDocumentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ValidateService_ServiceDesc is the grpc.ServiceDesc for ValidateService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -104,7 +236,22 @@ var ValidateService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Check",
 			Handler:    _ValidateService_Check_Handler,
 		},
+		{
+			MethodName: "BulkCheck",
+			Handler:    _ValidateService_BulkCheck_Handler,
+		},
+		{
+			MethodName: "CheckDocument",
+			Handler:    _ValidateService_CheckDocument_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "CheckStream",
+			Handler:       _ValidateService_CheckStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/validate/v1/validate.proto",
 }
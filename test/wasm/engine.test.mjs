@@ -0,0 +1,94 @@
+// Tests of the WebAssembly build run by `make wasm-test` (node --test).
+import assert from "node:assert/strict";
+import fs from "node:fs";
+import path from "node:path";
+import { before, describe, it } from "node:test";
+import { fileURLToPath } from "node:url";
+
+const root = path.join(path.dirname(fileURLToPath(import.meta.url)), "..", "..");
+const testdata = path.join(root, "test", "wasm", "testdata");
+
+let engine;
+
+before(async () => {
+  globalThis.fs ??= fs;
+  await import(path.join(root, "wasm", "wasm_exec.js"));
+  const { load } = await import(path.join(root, "wasm", "open-ve.mjs"));
+  engine = await load(fs.readFileSync(path.join(root, "wasm", "open-ve.wasm")));
+});
+
+describe("loadDSL", () => {
+  it("checks the rules of a YAML DSL", () => {
+    engine.loadDSL(`
+validations:
+  - id: item
+    variables:
+      - name: price
+        type: decimal
+      - name: name
+        type: string
+      - name: image
+        type: bytes
+    cels:
+      - price > decimal("0")
+      - name.graphemeLength() <= 5
+      - size(image) > 0
+`);
+    const image = new Uint8Array([0x89, 0x50, 0x4e, 0x47]);
+    assert.deepEqual(engine.check("item", { price: "1.50", name: "abc", image }), { isValid: true, message: "", errors: [] });
+
+    const result = engine.check("item", { price: "-1", name: "abcdef", image: "" });
+    assert.equal(result.isValid, false);
+    assert.equal(result.message, 'failed validations: price > decimal("0"), name.graphemeLength() <= 5, size(image) > 0');
+  });
+
+  it("checks the rules of a JSON DSL with a profile", () => {
+    engine.loadDSL(JSON.stringify({
+      validations: [{
+        id: "user",
+        variables: [{ name: "id", type: "string" }, { name: "age", type: "int" }],
+        cels: ["id != ''", "age >= 18"],
+        profiles: [{ name: "create", cels: ["id == ''"], exclude: ["id != ''"] }],
+      }],
+    }));
+    assert.equal(engine.check("user", { id: "", age: 20 }, { profile: "create" }).isValid, true);
+    assert.equal(engine.check("user", { id: "", age: 20 }).isValid, false);
+  });
+
+  it("throws on an invalid DSL", () => {
+    assert.throws(
+      () => engine.loadDSL("validations: [{id: item, variables: [{name: x, type: int}], cels: ['x >']}]"),
+      (error) => error.code === "DSLSyntaxError",
+    );
+  });
+});
+
+describe("loadBundle", () => {
+  const response = JSON.parse(fs.readFileSync(path.join(testdata, "bundle.json"), "utf8"));
+  const publicKey = fs.readFileSync(path.join(testdata, "bundle-key.pub"), "utf8");
+
+  it("checks the compiled rules of a signed bundle", () => {
+    engine.loadBundle(response, publicKey);
+    assert.equal(engine.check("item", { price: 10, country: "JP" }).isValid, true);
+
+    const result = engine.check("item", { price: -1, country: "FR" });
+    assert.equal(result.isValid, false);
+    assert.equal(result.message, 'failed validations: price > 0, inSet("countries", country)');
+
+    // the draft profile excludes price > 0, adds total < 100 and makes country optional
+    assert.equal(engine.check("item", { price: -1 }, { profile: "draft" }).isValid, true);
+    assert.equal(engine.check("item", { price: 60 }, { profile: "draft" }).isValid, false);
+  });
+
+  it("rejects a bundle with an invalid signature", () => {
+    const tampered = { ...response, signature: Buffer.alloc(64).toString("base64") };
+    assert.throws(() => engine.loadBundle(tampered, publicKey), /invalid bundle signature/);
+  });
+});
+
+describe("check", () => {
+  it("throws on a variable of a wrong type", () => {
+    engine.loadDSL("validations: [{id: item, variables: [{name: price, type: int}], cels: ['price > 0']}]");
+    assert.throws(() => engine.check("item", { price: "100" }), /invalid variable price/);
+  });
+});
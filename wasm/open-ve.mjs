@@ -0,0 +1,56 @@
+// JavaScript API of the Open-VE validation engine built by `make wasm`.
+// wasm_exec.js (copied next to this file by `make wasm`) must be loaded first, as it defines globalThis.Go.
+//
+//   const engine = await load(fetch("open-ve.wasm"));
+//   engine.loadDSL(yaml);
+//   const result = engine.check("item", { price: 100 });
+
+// load instantiates the engine from a Response (or a promise of it) or the bytes of open-ve.wasm.
+export async function load(source) {
+  const go = new globalThis.Go();
+  const resolved = await source;
+  const { instance } =
+    typeof Response !== "undefined" && resolved instanceof Response
+      ? await WebAssembly.instantiateStreaming(resolved, go.importObject)
+      : await WebAssembly.instantiate(resolved, go.importObject);
+  // main registers the functions and then waits for calls, so the promise never resolves
+  go.run(instance);
+  const api = globalThis.__openVE;
+
+  return {
+    // loadDSL registers a DSL written in YAML or JSON, replacing the loaded one.
+    loadDSL(source) {
+      unwrap(api.loadDSL(source));
+    },
+    // loadBundle registers the response of GET /v1/dsl/bundle, replacing the loaded DSL.
+    // The signature is verified when the PEM encoded Ed25519 public key is given.
+    loadBundle(response, publicKeyPEM) {
+      unwrap(api.loadBundle(JSON.stringify(response), publicKeyPEM ?? ""));
+    },
+    // check validates the variables given as in the JSON check request, except that bytes may also be Uint8Array.
+    // options are { profile, explain }. It returns { isValid, message, errors, explanations, computed }.
+    check(id, variables, options) {
+      return unwrap(api.check(id, JSON.stringify(variables, encodeBytes), JSON.stringify(options ?? {})));
+    },
+  };
+}
+
+function unwrap(ret) {
+  if (ret.error !== undefined) {
+    const error = new Error(ret.error);
+    error.code = ret.code;
+    throw error;
+  }
+  return ret.result === undefined ? undefined : JSON.parse(ret.result);
+}
+
+function encodeBytes(key, value) {
+  if (!(value instanceof Uint8Array)) {
+    return value;
+  }
+  let binary = "";
+  for (const byte of value) {
+    binary += String.fromCharCode(byte);
+  }
+  return btoa(binary);
+}